@@ -0,0 +1,139 @@
+package bdstockexchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// dseArchiveDays is the number of days requested from the dse day end archive at once.
+// A longer range is fetched page by page
+const dseArchiveDays = 90
+
+// dseArchiveColumns are the columns of the dse day end archive table
+var dseArchiveColumns = []column{
+	{"date", []string{"DATE"}},
+	{"trading_code", []string{"TRADING CODE", "CODE"}},
+	{"ltp", []string{"LTP*", "LTP"}},
+	{"high", []string{"HIGH"}},
+	{"low", []string{"LOW"}},
+	{"open", []string{"OPENP*", "OPENP", "OPEN"}},
+	{"close_p", []string{"CLOSEP*", "CLOSEP", "CLOSE PRICE"}},
+	{"ycp", []string{"YCP*", "YCP"}},
+	{"trade", []string{"TRADE"}},
+	{"value", []string{"VALUE (mn)", "VALUE (IN MN)", "VALUE"}},
+	{"volume", []string{"VOLUME"}},
+}
+
+// HistoricalPrice is the day end price of a share on a trading day of the dse archive
+type HistoricalPrice struct {
+	// Date is the trading day at midnight in the Dhaka location
+	Date        time.Time `json:"date"`
+	TradingCode string    `json:"trading_code"`
+	Open        Decimal   `json:"open"`
+	High        Decimal   `json:"high"`
+	Low         Decimal   `json:"low"`
+	Close       Decimal   `json:"close"`
+	LTP         Decimal   `json:"ltp"`
+	YCP         Decimal   `json:"ycp"`
+	Trade       int64     `json:"trade"`
+	ValueInMN   Decimal   `json:"value"`
+	Volume      int64     `json:"volume"`
+}
+
+// GetHistoricalPrices returns the day end prices of the share for every trading day from the from to the to date
+// inclusive, the oldest first. The days are taken in the Dhaka location and a long range is fetched from the dse
+// archive in pages of 90 days. It returns an error wrapping ErrInvalidArgument for an empty trading code or a range
+// which ends before it starts and ErrNoDataFound if the share was not traded in the range
+func (d *DSE) GetHistoricalPrices(ctx context.Context, code string, from, to time.Time) ([]*HistoricalPrice, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return nil, fmt.Errorf("%w: empty trading code", ErrInvalidArgument)
+	}
+	from, to = dhakaDate(from), dhakaDate(to)
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: the range ends on %s before it starts on %s", ErrInvalidArgument,
+			to.Format("2006-01-02"), from.Format("2006-01-02"))
+	}
+
+	prices := make([]*HistoricalPrice, 0)
+	seen := make(map[time.Time]bool)
+	for start := from; !start.After(to); start = start.AddDate(0, 0, dseArchiveDays) {
+		end := start.AddDate(0, 0, dseArchiveDays-1)
+		if end.After(to) {
+			end = to
+		}
+		page, err := d.getArchive(ctx, code, start, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, price := range page {
+			if seen[price.Date] || price.Date.Before(from) || price.Date.After(to) {
+				continue
+			}
+			seen[price.Date] = true
+			prices = append(prices, price)
+		}
+	}
+	if len(prices) == 0 {
+		return nil, fmt.Errorf("%w: %s was not traded from %s to %s", ErrNoDataFound, code,
+			from.Format("2006-01-02"), to.Format("2006-01-02"))
+	}
+
+	sort.SliceStable(prices, func(i, j int) bool {
+		return prices[i].Date.Before(prices[j].Date)
+	})
+	return prices, nil
+}
+
+// getArchive returns the rows of the share in a page of the dse day end archive
+func (d *DSE) getArchive(ctx context.Context, code string, from, to time.Time) ([]*HistoricalPrice, error) {
+	url := d.url(fmt.Sprintf("/day_end_archive.php?startDate=%s&endDate=%s&inst=%s&archive=data",
+		from.Format("2006-01-02"), to.Format("2006-01-02"), url.QueryEscape(code)))
+	doc, err := d.client.loadURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := findTable(doc, url, dseArchiveColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make([]*HistoricalPrice, 0, len(t.rows))
+	p := &cellParser{url: url}
+	for row, cells := range t.rows {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(t.text(cells, "trading_code"), code) {
+			continue
+		}
+		p.row = row
+		price := &HistoricalPrice{
+			Date:        p.date("date", t.text(cells, "date"), dateLayouts...),
+			TradingCode: code,
+			Open:        p.decimal("open", t.text(cells, "open")),
+			High:        p.decimal("high", t.text(cells, "high")),
+			Low:         p.decimal("low", t.text(cells, "low")),
+			Close:       p.decimal("close_p", t.text(cells, "close_p")),
+			LTP:         p.decimal("ltp", t.text(cells, "ltp")),
+			YCP:         p.decimal("ycp", t.text(cells, "ycp")),
+			Trade:       p.int64("trade", t.text(cells, "trade")),
+			ValueInMN:   p.decimal("value", t.text(cells, "value")),
+			Volume:      p.int64("volume", t.text(cells, "volume")),
+		}
+		if p.err == nil && price.Date.IsZero() {
+			p.fail("date", t.text(cells, "date"), errors.New("empty date"))
+		}
+		if p.err != nil {
+			return nil, p.err
+		}
+		prices = append(prices, price)
+	}
+	return prices, nil
+}
@@ -0,0 +1,62 @@
+package bdstockexchange
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDSE_GetHistoricalPrices(t *testing.T) {
+	day := func(m time.Month, d int) time.Time {
+		return time.Date(2020, m, d, 0, 0, 0, 0, Dhaka)
+	}
+	tests := []struct {
+		name     string
+		code     string
+		from, to time.Time
+		want     int
+		wantErr  error
+	}{
+		{"two pages", "gp", day(time.June, 1), day(time.October, 15), 5, nil},
+		{"weekend", "GP", day(time.October, 16), day(time.October, 17), 0, ErrNoDataFound},
+		{"empty code", " ", day(time.June, 1), day(time.October, 15), 0, ErrInvalidArgument},
+		{"reversed range", "GP", day(time.October, 15), day(time.June, 1), 0, ErrInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testDSE().GetHistoricalPrices(context.Background(), tt.code, tt.from, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetHistoricalPrices() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Fatalf("GetHistoricalPrices() = %d prices, want %d", len(got), tt.want)
+			}
+			for i := 1; i < len(got); i++ {
+				if !got[i-1].Date.Before(got[i].Date) {
+					t.Errorf("GetHistoricalPrices() %v is not before %v", got[i-1].Date, got[i].Date)
+				}
+			}
+			if tt.name == "two pages" {
+				assertGolden(t, "dse_historical_prices_gp", got)
+			}
+		})
+	}
+}
+
+func TestDSE_GetHistoricalPrices_escape(t *testing.T) {
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query().Get("inst")
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+
+	day := time.Date(2020, time.October, 15, 0, 0, 0, 0, Dhaka)
+	NewDSE(WithBaseURL(ts.URL)).GetHistoricalPrices(context.Background(), "A&B #1", day, day)
+	if want := "A&B #1"; got != want {
+		t.Errorf("GetHistoricalPrices() requested the share %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,572 @@
+package bdstockexchangetest
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// cseCompanyURL is the url the cse website links the company details with. The trading code is read from it
+const cseCompanyURL = "https://www.cse.com.bd/company/companydetails/"
+
+// cseLayout is the layout of every cse page with the market status. The pages define the content template
+const cseLayout = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} | Chittagong Stock Exchange</title>
+</head>
+<body>
+<div id="wrapper">
+<div class="wrapper_inner">
+<header class="header">
+<div class="container">
+<div class="row">
+<div class="col-md-3 logo"><a href="https://www.cse.com.bd/">CSE</a></div>
+<div class="col-md-9">
+<div class="market_status">
+<div class="status_text">Market Status : <span>{{.Status}}</span></div>
+<div class="status_time">{{.Day}}</div>
+</div>
+</div>
+</div>
+</div>
+</header>
+<div class="main_content">
+<div class="container">
+<div class="col-md-9 content_left">
+{{template "content" .}}
+</div>
+<div class="col-md-3 sidebar"></div>
+</div>
+</div>
+</div>
+</div>
+</body>
+</html>
+{{define "form"}}<div class="search_form">
+<div class="form_inner">
+<div class="form_wrap">
+<form method="post" action="{{.Action}}">
+<input type="hidden" name="_token" value="{{.Token}}">
+<div class="row">
+<div class="col-md-6"><label>Select</label></div>
+<div class="col-md-6">
+{{.Input}}
+<button type="submit">Search</button>
+</div>
+</div>
+</form>
+</div>
+</div>
+</div>{{end}}
+{{define "companies"}}{{range .}}<li><a href="{{companyURL .TradingCode}}">{{.Name}}</a></li>
+{{end}}{{end}}
+`
+
+// cseTemplate returns the cse layout with the content template of a page
+func cseTemplate(content string) *template.Template {
+	t := template.Must(template.New("cse").Funcs(funcs).Funcs(template.FuncMap{
+		"companyURL": func(code string) string { return cseCompanyURL + code },
+	}).Parse(cseLayout))
+	template.Must(t.New("content").Parse(content))
+	return t
+}
+
+var cseHomeTemplate = cseTemplate(`<div class="page_title"><h1>Market Summary</h1></div>
+<div class="market_summary">
+<div class="table_wrapper">
+<table class="table index_table">
+<thead>
+<tr>
+<th>Index</th>
+<th>Value</th>
+<th>Change</th>
+<th>% Change</th>
+</tr>
+</thead>
+<tbody>
+{{range .Indices}}<tr>
+<td>{{.Name}}</td>
+<td>{{fixed .Value 2}}</td>
+<td>{{fixed .Change 2}}</td>
+<td>{{fixed .Percentage 2}}%</td>
+</tr>
+{{end}}</tbody>
+</table>
+</div>
+<div class="table_wrapper">
+<table class="table trade_table">
+<thead>
+<tr>
+<th>Trade</th>
+<th>Volume</th>
+<th>Value (In Mn)</th>
+<th>Advanced</th>
+<th>Declined</th>
+<th>Unchanged</th>
+</tr>
+</thead>
+<tbody>
+<tr>
+<td>{{integer .TotalTrade}}</td>
+<td>{{integer .TotalVolume}}</td>
+<td>{{fixed .TotalValueInMN 3}}</td>
+<td>{{.IssuesAdvanced}}</td>
+<td>{{.IssuesDeclined}}</td>
+<td>{{.IssuesUnchanged}}</td>
+</tr>
+</tbody>
+</table>
+</div>
+</div>`)
+
+var cseCurrentPriceTemplate = cseTemplate(`<div class="page_title"><h1>Current Price</h1></div>
+<div class="filter"></div>
+<div class="table_wrapper">
+<table id="dataTable" class="table table-striped">
+<thead>
+<tr>
+<th>SL</th>
+<th>Stock Code</th>
+<th>LTP</th>
+<th>Open</th>
+<th>High</th>
+<th>Low</th>
+<th>YCP</th>
+<th>Trade</th>
+<th>Value (In Mn)</th>
+<th>Volume</th>
+</tr>
+</thead>
+<tbody>
+{{range $i, $s := .Shares}}<tr>
+<td>{{inc $i}}</td>
+<td><a href="{{companyURL $s.TradingCode}}">{{$s.TradingCode}}</a></td>
+<td>{{number $s.LTP}}</td>
+<td>{{number $s.Open}}</td>
+<td>{{number $s.High}}</td>
+<td>{{number $s.Low}}</td>
+<td>{{number $s.YCP}}</td>
+<td>{{$s.Trade}}</td>
+<td>{{number $s.ValueInMN}}</td>
+<td>{{integer $s.Volume}}</td>
+</tr>
+{{end}}</tbody>
+</table>
+</div>`)
+
+var cseHistoricalMarketTemplate = cseTemplate(`<div class="page_title"><h1>Historical Market</h1></div>
+<div class="filter"></div>
+<div class="historical_market">
+<div class="highscore">
+<div class="highscore_wrap">
+<div class="highscore_inner">
+<div class="highscore_list">
+<div class="highscore_tabs_head"><div>Record</div><div>Value</div><div>Date</div></div>
+{{range .Records}}<div class="highscore_tabs_cont">
+<div id="highscore_tab_1">{{.Title}}</div>
+<div id="highscore_tab_2">{{number .Value}}</div>
+<div id="highscore_tab_3">{{.Date}}</div>
+</div>
+{{end}}</div>
+</div>
+</div>
+</div>
+<div class="market">
+<div class="market_wrap">
+<div class="market_inner">
+<div class="market_list">
+<div class="market_tabs_head"><div>SL</div><div>Date</div><div>Trade</div><div>Volume</div><div>Value (Tk)</div><div>Market Cap (mn)</div><div>CSE30</div><div>CSCX</div><div>CASPI</div><div>CSE50</div><div>CSI</div></div>
+{{range $i, $d := .History}}<div class="market_tabs_cont">
+<div id="market_tab_1">{{inc $i}}</div>
+<div id="market_tab_2">{{$d.Date}}</div>
+<div id="market_tab_3">{{integer $d.Trade}}</div>
+<div id="market_tab_4">{{integer $d.Volume}}</div>
+<div id="market_tab_5">{{number $d.ValueInTK}}</div>
+<div id="market_tab_6">{{fixed $d.MarketCapInMN 2}}</div>
+<div id="market_tab_7">{{fixed $d.CSE30 2}}</div>
+<div id="market_tab_8">{{fixed $d.CSCX 2}}</div>
+<div id="market_tab_9">{{fixed $d.CASPI 2}}</div>
+<div id="market_tab_10">{{fixed $d.CSE50 2}}</div>
+<div id="market_tab_11">{{fixed $d.CSI 2}}</div>
+</div>
+{{end}}</div>
+</div>
+</div>
+</div>
+</div>`)
+
+var cseWeeklyReportTemplate = cseTemplate(`{{template "form" .Form}}
+<div class="page_title"><h1>Weekly Report</h1></div>
+<div class="pe_ratio">
+<div class="pe_ratio_wrap">
+<div class="pe_ratio_inner">
+<div class="pe_ratio_tabs_head"><div>Date</div><div>Title</div></div>
+<div class="pe_ratio_body">
+<div class="pe_ratio_list">
+{{range .Reports}}<div class="pe_ratio_tabs_cont">
+<div id="pe_ratiocont_1">{{.Date}}</div>
+<div id="pe_ratiocont_2"><a href="{{$.PDFURL}}{{.File}}" target="_blank">{{.Title}}</a></div>
+</div>
+{{end}}</div>
+</div>
+</div>
+</div>
+</div>`)
+
+var csePriceEarningRatioTemplate = cseTemplate(`{{template "form" .Form}}
+<div class="page_title"><h1>P/E Ratio</h1></div>
+<div class="pe_ratio">
+<div class="pe_ratio_wrap">
+<div class="pe_ratio_inner">
+<div class="pe_ratio_list">
+<div class="pe_ratio_group">
+{{range $i, $r := .Ratios}}<div class="pe_ratio_tabs_cont">
+<div id="pe_ratiocont_1">{{inc $i}}.</div>
+<div id="pe_ratiocont_2">{{$r.TradingCode}}</div>
+<div id="pe_ratiocont_3"><table><tbody><tr><td>{{$r.FinancialYearFrom}}</td><td>{{$r.FinancialYearTo}}</td></tr></tbody></table></div>
+<div id="pe_ratiocont_4"><table><tbody><tr><td>{{fixed $r.Quarter1 2}}</td><td>{{fixed $r.HalfYear 2}}</td><td>{{fixed $r.Quarter3 2}}</td></tr></tbody></table></div>
+<div id="pe_ratiocont_5">{{fixed $r.AnnualizedEPS 2}}</div>
+<div id="pe_ratiocont_6">{{fixed $r.AuditedEPS 2}}</div>
+<div id="pe_ratiocont_7">{{fixed $r.ClosePrice 2}}</div>
+<div id="pe_ratiocont_8">{{ratio $r.PEAnnualized}}</div>
+<div id="pe_ratiocont_9">{{ratio $r.PEAudited}}</div>
+</div>
+{{end}}</div>
+</div>
+</div>
+</div>
+</div>`)
+
+var cseListedCompaniesTemplate = cseTemplate(`<div class="page_title"><h1>Listed Companies</h1></div>
+<div class="tab-content">
+<div id="top_content_1" class="tab-pane active">
+<div class="company_wrap"><div class="company_inner"><div class="company_list"><div class="company_cont"><div class="company_box"><div class="company_row">
+<div class="company_alpha">All</div>
+<div class="company_names">
+<div class="company_col">
+<ul>
+{{template "companies" .Companies}}</ul>
+</div>
+</div>
+</div></div></div></div></div></div>
+</div>
+<div id="top_content_2" class="tab-pane">
+<div class="company_wrap"><div class="company_inner"><div class="company_list"><div class="company_cont"><div class="company_box">
+{{range .Industries}}<div id="{{.Name}}">
+<h4>{{.Name}}</h4>
+<ul>
+{{template "companies" .Companies}}</ul>
+</div>
+{{end}}</div></div></div></div></div>
+</div>
+<div id="top_content_3" class="tab-pane">
+<div class="company_wrap"><div class="company_inner"><div class="company_list"><div class="company_cont"><div class="company_box">
+{{range .Categories}}<div class="category_{{.Name}}">
+<div class="category_name">{{.Name}}</div>
+<ul>
+{{template "companies" .Companies}}</ul>
+</div>
+{{end}}</div></div></div></div></div>
+</div>
+</div>`)
+
+var cseCompanyDetailsTemplate = cseTemplate(`<div class="page_title"><h1>{{.Company.Name}}</h1></div>
+<div class="company_details">
+<h2>Company Information</h2>
+<table class="table company_info">
+<tr><td>Company Name</td><td>{{.Company.Name}}</td></tr>
+<tr><td>Stock Code</td><td>{{.Company.TradingCode}}</td></tr>
+<tr><td>Sector</td><td>{{.Company.Industry}}</td></tr>
+<tr><td>Category</td><td>{{.Company.Category}}</td></tr>
+<tr><td>Listing Year</td><td>{{.Company.ListingYear}}</td></tr>
+<tr><td>Authorized Capital (mn)</td><td>{{fixed .Company.AuthorizedCapitalInMN 2}}</td></tr>
+<tr><td>Paid Up Capital (mn)</td><td>{{fixed .Company.PaidUpCapitalInMN 2}}</td></tr>
+<tr><td>Face Value</td><td>{{fixed .Company.FaceValue 2}}</td></tr>
+<tr><td>Market Lot</td><td>{{integer .Company.MarketLot}}</td></tr>
+<tr><td>Total Securities</td><td>{{integer .Company.TotalSecurities}}</td></tr>
+</table>
+<h2>Market Information</h2>
+<table class="table market_info">
+<tr><td>Last Trade Price</td><td>{{fixed .Share.LTP 2}}</td></tr>
+<tr><td>Yesterday Closing Price</td><td>{{fixed .Share.YCP 2}}</td></tr>
+<tr><td>Day's Range</td><td>{{if .Share.TradingCode}}{{fixed .Share.Low 2}} - {{fixed .Share.High 2}}{{else}}-{{end}}</td></tr>
+<tr><td>52 Weeks Range</td><td>{{if .Company.Week52High}}{{fixed .Company.Week52Low 2}} - {{fixed .Company.Week52High 2}}{{else}}N/A{{end}}</td></tr>
+<tr><td>Volume</td><td>{{integer .Share.Volume}}</td></tr>
+<tr><td>Value (mn)</td><td>{{fixed .Share.ValueInMN 3}}</td></tr>
+<tr><td>Market Capitalization (mn)</td><td>{{fixed .MarketCapInMN 2}}</td></tr>
+</table>
+<h2>Shareholding Pattern as on {{.ShareholdingDate}}</h2>
+<table class="table shareholding">
+<tr><th>Sponsor/Director</th><th>Govt</th><th>Institute</th><th>Foreign</th><th>Public</th></tr>
+<tr>{{range .Company.Shareholding}}<td>{{fixed . 2}}</td>{{end}}</tr>
+</table>
+<h2>Financial Highlights</h2>
+<table class="table highlights">
+<tr><th>Year</th><th>EPS</th><th>NAV Per Share</th><th>Net Profit (mn)</th></tr>
+{{range .Company.FinancialHighlights}}<tr><td>{{.Year}}</td><td>{{fixed .EPS 2}}</td><td>{{fixed .NAVPerShare 2}}</td><td>{{fixed .NetProfitInMN 2}}</td></tr>
+{{end}}</table>
+</div>`)
+
+// csePage holds the data of the cse page layout
+type csePage struct {
+	Title  string
+	Status string
+	Day    string
+}
+
+// newCSEPage returns the layout data of a cse page for the market
+func newCSEPage(m *Market, title string) csePage {
+	return csePage{
+		Title:  title,
+		Status: status(m.Open),
+		Day:    dhakaTime(m.LastUpdate).Format("02 Jan 2006"),
+	}
+}
+
+// cseForm is the search form of the cse weekly report and price earning ratio pages.
+// Token is the csrf token of the session the form is posted with
+type cseForm struct {
+	Action string
+	Input  template.HTML
+	Token  string
+}
+
+// companyGroup is a named group of the listed companies page, ex: an industry or a category
+type companyGroup struct {
+	Name      string
+	Companies []*Company
+}
+
+func (s *Server) handleCSEHome(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/cse/" {
+		http.NotFound(w, r)
+		return
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	m := s.market
+	data := struct {
+		csePage
+		Indices                                         []indexRow
+		TotalTrade, TotalVolume                         int64
+		TotalValueInMN                                  float64
+		IssuesAdvanced, IssuesDeclined, IssuesUnchanged int
+	}{csePage: newCSEPage(m, "Home")}
+	if len(m.CSEHistory) > 0 {
+		latest, previous := m.CSEHistory[0], m.CSEHistory[0]
+		if len(m.CSEHistory) > 1 {
+			previous = m.CSEHistory[1]
+		}
+		for _, i := range []struct {
+			name            string
+			value, previous float64
+		}{
+			{"CASPI", latest.CASPI, previous.CASPI},
+			{"CSE30", latest.CSE30, previous.CSE30},
+			{"CSCX", latest.CSCX, previous.CSCX},
+			{"CSE50", latest.CSE50, previous.CSE50},
+			{"CSI", latest.CSI, previous.CSI},
+		} {
+			data.Indices = append(data.Indices, indexRow{
+				Name:       i.name,
+				Value:      i.value,
+				Change:     i.value - i.previous,
+				Percentage: percentage(i.value, i.previous),
+			})
+		}
+	}
+	for _, share := range m.CSEShares {
+		data.TotalTrade += share.Trade
+		data.TotalVolume += share.Volume
+		data.TotalValueInMN += share.ValueInMN
+		switch {
+		case share.LTP > share.YCP:
+			data.IssuesAdvanced++
+		case share.LTP < share.YCP:
+			data.IssuesDeclined++
+		default:
+			data.IssuesUnchanged++
+		}
+	}
+	s.render(w, cseHomeTemplate, data)
+}
+
+func (s *Server) handleCSECurrentPrice(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data := struct {
+		csePage
+		Shares []*Share
+	}{newCSEPage(s.market, "Current Price"), s.market.CSEShares}
+	s.render(w, cseCurrentPriceTemplate, data)
+}
+
+func (s *Server) handleCSEHistoricalMarket(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data := struct {
+		csePage
+		Records []*HighestRecord
+		History []*MarketDay
+	}{newCSEPage(s.market, "Historical Market"), s.market.CSEHighestRecords, s.market.CSEHistory}
+	s.render(w, cseHistoricalMarketTemplate, data)
+}
+
+func (s *Server) handleCSEWeeklyReport(w http.ResponseWriter, r *http.Request) {
+	if !s.checkCSRF(r) {
+		http.Error(w, "Page Expired", statusPageExpired)
+		return
+	}
+	token := s.cseSession(w, r)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	years := make([]int, 0, len(s.market.CSEWeeklyReports))
+	for year := range s.market.CSEWeeklyReports {
+		years = append(years, year)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(years)))
+
+	var input bytes.Buffer
+	input.WriteString("<select name=\"Year\">\n<option value=\"\"></option>\n")
+	for _, year := range years {
+		input.WriteString("<option value=\"" + strconv.Itoa(year) + "\">" + strconv.Itoa(year) + "</option>\n")
+	}
+	input.WriteString("</select>")
+
+	var reports []*WeeklyReport
+	if year, err := strconv.Atoi(r.FormValue("Year")); err == nil {
+		reports = s.market.CSEWeeklyReports[year]
+	}
+	data := struct {
+		csePage
+		Form    cseForm
+		PDFURL  string
+		Reports []*WeeklyReport
+	}{
+		csePage: newCSEPage(s.market, "Weekly Report"),
+		Form:    cseForm{Action: s.CSEURL() + "/market/weekly_report", Input: template.HTML(input.String()), Token: token},
+		PDFURL:  s.CSEURL() + "/assets/weekly_report/",
+		Reports: reports,
+	}
+	s.render(w, cseWeeklyReportTemplate, data)
+}
+
+func (s *Server) handleCSEWeeklyReportPDF(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	file := path.Base(r.URL.Path)
+	for _, reports := range s.market.CSEWeeklyReports {
+		for _, report := range reports {
+			if report.File != file {
+				continue
+			}
+			b := report.PDF
+			if b == nil && report.Statistics != nil {
+				b = weeklyReportPDF(report)
+			}
+			if b != nil {
+				w.Header().Set("Content-Type", "application/pdf")
+				http.ServeContent(w, r, file, time.Time{}, bytes.NewReader(b))
+				return
+			}
+		}
+	}
+	http.NotFound(w, r)
+}
+
+func (s *Server) handleCSEPriceEarningRatio(w http.ResponseWriter, r *http.Request) {
+	if !s.checkCSRF(r) {
+		http.Error(w, "Page Expired", statusPageExpired)
+		return
+	}
+	token := s.cseSession(w, r)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data := struct {
+		csePage
+		Form   cseForm
+		Ratios []*PriceEarningRatio
+	}{
+		csePage: newCSEPage(s.market, "P/E Ratio"),
+		Form:    cseForm{Action: s.CSEURL() + "/market/pe_ratio", Input: `<input type="date" name="pe_date">`, Token: token},
+		Ratios:  s.market.CSEPriceEarningRatios[r.FormValue("pe_date")],
+	}
+	s.render(w, csePriceEarningRatioTemplate, data)
+}
+
+func (s *Server) handleCSEListedCompanies(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	industries := make([]*companyGroup, 0)
+	categories := make([]*companyGroup, 0)
+	group := func(groups []*companyGroup, name string, c *Company) []*companyGroup {
+		for _, g := range groups {
+			if g.Name == name {
+				g.Companies = append(g.Companies, c)
+				return groups
+			}
+		}
+		return append(groups, &companyGroup{Name: name, Companies: []*Company{c}})
+	}
+	for _, c := range s.market.CSECompanies {
+		industries = group(industries, c.Industry, c)
+		categories = group(categories, c.Category, c)
+	}
+	sort.SliceStable(categories, func(i, j int) bool {
+		return categories[i].Name < categories[j].Name
+	})
+
+	data := struct {
+		csePage
+		Companies  []*Company
+		Industries []*companyGroup
+		Categories []*companyGroup
+	}{newCSEPage(s.market, "Listed Companies"), s.market.CSECompanies, industries, categories}
+	s.render(w, cseListedCompaniesTemplate, data)
+}
+
+func (s *Server) handleCSECompanyDetails(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	code := path.Base(r.URL.Path)
+	company, share := &Company{}, &Share{}
+	for _, c := range s.market.CSECompanies {
+		if c.TradingCode == code {
+			company = c
+		}
+	}
+	for _, sh := range s.market.CSEShares {
+		if sh.TradingCode == code {
+			share = sh
+		}
+	}
+	var shareholdingDate string
+	if t, err := time.Parse("2006-01-02", company.ShareholdingDate); err == nil {
+		shareholdingDate = t.Format("Jan 2, 2006")
+	}
+	data := struct {
+		csePage
+		Company          *Company
+		Share            *Share
+		MarketCapInMN    float64
+		ShareholdingDate string
+	}{
+		csePage:          newCSEPage(s.market, company.Name),
+		Company:          company,
+		Share:            share,
+		MarketCapInMN:    share.LTP * float64(company.TotalSecurities) / 1e6,
+		ShareholdingDate: shareholdingDate,
+	}
+	s.render(w, cseCompanyDetailsTemplate, data)
+}
@@ -0,0 +1,762 @@
+package bdstockexchangetest
+
+import (
+	"html/template"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dseHeader is the header of every dse page with the market status
+const dseHeader = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+<div class="Header">
+<div class="container">
+<div class="row">
+<header>
+<div class="HeaderTop">
+<span class="time">{{.Day}}</span>
+<span class="green">Welcome to Dhaka Stock Exchange</span>
+<span class="blue">Market Status: <span class="green"><b>{{.Status}}</b></span></span>
+</div>
+</header>
+</div>
+</div>
+</div>
+`
+
+var dseHomeTemplate = template.Must(template.New("dse_home").Funcs(funcs).Parse(dseHeader + `<div class="container">
+<section class="body-section">
+<div class="row">
+<div class="col-md-8">
+<div class="LeftColHome">
+<h2 class="BodyHead topBodyHead">Last update on {{.LastUpdate}}</h2>
+{{range .Indices}}<div class="midrow">
+<div class="m_col-1">{{.Name}} Index</div>
+<div class="m_col-wid">{{fixed .Value 2}}</div>
+<div class="m_col-wid1">{{fixed .Change 2}}</div>
+<div class="m_col-wid2">{{fixed .Percentage 2}}%</div>
+</div>
+{{end}}<div class="midrow">
+<div class="m_col-wid">Total Trade</div>
+<div class="m_col-wid">Total Volume</div>
+<div class="m_col-wid">Total Value in Taka (mn)</div>
+</div>
+<div class="midrow">
+<div class="m_col-wid">{{integer .TotalTrade}}</div>
+<div class="m_col-wid">{{integer .TotalVolume}}</div>
+<div class="m_col-wid">{{fixed .TotalValueInMN 3}}</div>
+</div>
+<div class="midrow">
+<div class="m_col-wid">Issues Advanced</div>
+<div class="m_col-wid">Issues declined</div>
+<div class="m_col-wid">Issues Unchanged</div>
+</div>
+<div class="midrow">
+<div class="m_col-wid">{{.IssuesAdvanced}}</div>
+<div class="m_col-wid">{{.IssuesDeclined}}</div>
+<div class="m_col-wid">{{.IssuesUnchanged}}</div>
+</div>
+<div class="midrow">
+<div class="m_col-wid">Total Market Capitalization in Taka (mn)</div>
+<div class="m_col-wid">Equity Market Capitalization in Taka (mn)</div>
+</div>
+<div class="midrow">
+<div class="m_col-wid">{{fixed .MarketCapInMN 3}}</div>
+<div class="m_col-wid">{{fixed .EquityMarketCapInMN 3}}</div>
+</div>
+</div>
+</div>
+<div class="col-md-4">
+<div class="RightColHome">
+{{range .Movers}}<h2 class="BodyHead">{{.Heading}}</h2>
+<div class="table-responsive">
+<table class="table table-bordered background-white">
+<thead>
+<tr>
+<th>#</th>
+<th>TRADING CODE</th>
+<th>LTP</th>
+<th>HIGH</th>
+<th>LOW</th>
+<th>CLOSEP</th>
+<th>YCP</th>
+<th>% CHANGE</th>
+<th>TRADE</th>
+<th>VALUE (mn)</th>
+<th>VOLUME</th>
+</tr>
+</thead>
+<tbody>
+{{range $i, $s := .Shares}}<tr>
+<td>{{inc $i}}</td>
+<td><a href="displayCompany.php?name={{$s.TradingCode}}" class="ab1">{{$s.TradingCode}}</a></td>
+<td>{{number $s.LTP}}</td>
+<td>{{number $s.High}}</td>
+<td>{{number $s.Low}}</td>
+<td>{{number $s.CloseP}}</td>
+<td>{{number $s.YCP}}</td>
+<td>{{fixed $s.Percentage 2}}%</td>
+<td>{{integer $s.Trade}}</td>
+<td>{{number $s.ValueInMN}}</td>
+<td>{{integer $s.Volume}}</td>
+</tr>
+{{end}}</tbody>
+</table>
+</div>
+{{end}}</div>
+</div>
+</div>
+</section>
+</div>
+</body>
+</html>
+`))
+
+var dsePricesTemplate = template.Must(template.New("dse_prices").Funcs(funcs).Parse(dseHeader + `<div class="container">
+<section class="body-section">
+<div class="row">
+<div class="col-md-12">
+<h2 class="BodyHead topBodyHead">{{.Heading}} On {{.LastUpdate}}</h2>
+</div>
+</div>
+<div class="row">
+<div class="col-md-12">
+<div class="table-responsive inner-scroll">
+<table class="table table-bordered background-white shares-table fixedHeader">
+<thead>
+<tr>
+<th width="4%">#</th>
+<th width="12%">TRADING CODE</th>
+<th width="8%">LTP*</th>
+<th width="8%">HIGH</th>
+<th width="8%">LOW</th>
+<th width="8%">CLOSEP*</th>
+<th width="8%">YCP*</th>
+<th width="8%">{{if .Percentage}}% CHANGE{{else}}CHANGE{{end}}</th>
+<th width="8%">TRADE</th>
+<th width="8%">VALUE (mn)</th>
+<th width="8%">VOLUME</th>
+</tr>
+</thead>
+{{range $i, $s := .Shares}}<tbody>
+<tr>
+<td width="4%">{{inc $i}}</td>
+<td width="12%"><a href="displayCompany.php?name={{$s.TradingCode}}" class="ab1">{{$s.TradingCode}}</a></td>
+<td width="8%">{{number $s.LTP}}</td>
+<td width="8%">{{number $s.High}}</td>
+<td width="8%">{{number $s.Low}}</td>
+<td width="8%">{{number $s.CloseP}}</td>
+<td width="8%">{{number $s.YCP}}</td>
+<td width="8%">{{if $.Percentage}}{{fixed $s.Percentage 2}}%{{else}}{{number $s.Change}}{{end}}</td>
+<td width="8%">{{integer $s.Trade}}</td>
+<td width="8%">{{number $s.ValueInMN}}</td>
+<td width="8%">{{integer $s.Volume}}</td>
+</tr>
+</tbody>
+{{end}}</table>
+</div>
+</div>
+</div>
+</section>
+</div>
+</body>
+</html>
+`))
+
+var dseArchiveTemplate = template.Must(template.New("dse_archive").Funcs(funcs).Parse(dseHeader + `<div class="container">
+<section class="body-section">
+<div class="row">
+<div class="col-md-12">
+<h2 class="BodyHead topBodyHead">Day End Summary (Historical Data)</h2>
+</div>
+</div>
+<div class="row">
+<div class="col-md-12">
+<div class="table-responsive inner-scroll">
+<table class="table table-bordered background-white shares-table fixedHeader">
+<thead>
+<tr>
+<th width="4%">#</th>
+<th width="10%">DATE</th>
+<th width="12%">TRADING CODE</th>
+<th width="8%">LTP*</th>
+<th width="8%">HIGH</th>
+<th width="8%">LOW</th>
+<th width="8%">OPENP*</th>
+<th width="8%">CLOSEP*</th>
+<th width="8%">YCP</th>
+<th width="8%">TRADE</th>
+<th width="8%">VALUE (mn)</th>
+<th width="8%">VOLUME</th>
+</tr>
+</thead>
+{{range $i, $p := .Prices}}<tbody>
+<tr>
+<td width="4%">{{inc $i}}</td>
+<td width="10%">{{$p.Date}}</td>
+<td width="12%"><a href="displayCompany.php?name={{$p.TradingCode}}" class="ab1">{{$p.TradingCode}}</a></td>
+<td width="8%">{{fixed $p.LTP 1}}</td>
+<td width="8%">{{fixed $p.High 1}}</td>
+<td width="8%">{{fixed $p.Low 1}}</td>
+<td width="8%">{{fixed $p.Open 1}}</td>
+<td width="8%">{{fixed $p.CloseP 1}}</td>
+<td width="8%">{{fixed $p.YCP 1}}</td>
+<td width="8%">{{integer $p.Trade}}</td>
+<td width="8%">{{fixed $p.ValueInMN 3}}</td>
+<td width="8%">{{integer $p.Volume}}</td>
+</tr>
+</tbody>
+{{end}}</table>
+</div>
+</div>
+</div>
+</section>
+</div>
+</body>
+</html>
+`))
+
+var dseCompanyTemplate = template.Must(template.New("dse_company").Funcs(funcs).Parse(dseHeader + `<div class="container">
+<section class="body-section">
+<h2 class="BodyHead topBodyHead">Company Name: <i>{{.Company.Name}}</i></h2>
+<div class="table-responsive">
+<table class="table table-bordered background-white" id="company">
+<tr>
+<th width="25%">Trading Code: {{.Company.TradingCode}}</th>
+<th width="25%">Scrip Code: {{.Company.ScripCode}}</th>
+</tr>
+</table>
+</div>
+{{with .Company}}{{if .TradingCode}}<h2 class="BodyHead topBodyHead">Basic Information</h2>
+<div class="table-responsive">
+<table class="table table-bordered background-white" id="company">
+<tr>
+<th width="25%">Authorized Capital (mn)</th>
+<td width="25%">{{fixed .AuthorizedCapitalInMN 2}}</td>
+<th width="25%">Debut Trading Date</th>
+<td width="25%">{{.DebutTradingDate}}</td>
+</tr>
+<tr>
+<th>Paid-up Capital (mn)</th>
+<td>{{fixed .PaidUpCapitalInMN 2}}</td>
+<th>Type of Instrument</th>
+<td>Equity</td>
+</tr>
+<tr>
+<th>Face/par Value</th>
+<td>{{fixed .FaceValue 1}}</td>
+<th>Market Lot</th>
+<td>{{.MarketLot}}</td>
+</tr>
+<tr>
+<th>Total No. of Outstanding Securities</th>
+<td>{{integer .TotalSecurities}}</td>
+<th>Sector</th>
+<td>{{.Industry}}</td>
+</tr>
+</table>
+</div>
+<h2 class="BodyHead topBodyHead">Corporate Performance</h2>
+<div class="table-responsive">
+<table class="table table-bordered background-white" id="company">
+<tr>
+<td width="25%">Last AGM held on:</td>
+<td width="75%">{{$.LastAGM}}</td>
+</tr>
+<tr>
+<td>Cash Dividend:</td>
+<td>{{$.CashDividends}}</td>
+</tr>
+<tr>
+<td>Bonus Issue (Stock Dividend):</td>
+<td>{{$.StockDividends}}</td>
+</tr>
+</table>
+</div>
+<h2 class="BodyHead topBodyHead">Financial Performance as per Audited Financial Statements</h2>
+<div class="table-responsive">
+<table class="table table-bordered background-white" id="company">
+<thead>
+<tr>
+<th>Year</th>
+<th>EPS</th>
+<th>NAV Per Share</th>
+<th>Profit (mn)</th>
+</tr>
+</thead>
+<tbody>
+{{range .FinancialHighlights}}<tr>
+<td>{{.Year}}</td>
+<td>{{fixed .EPS 2}}</td>
+<td>{{fixed .NAVPerShare 2}}</td>
+<td>{{fixed .NetProfitInMN 2}}</td>
+</tr>
+{{end}}</tbody>
+</table>
+</div>
+<h2 class="BodyHead topBodyHead">Other Information of the Company</h2>
+<div class="table-responsive">
+<table class="table table-bordered background-white" id="company">
+<tr>
+<td width="25%">Listing Year</td>
+<td width="75%">{{.ListingYear}}</td>
+</tr>
+<tr>
+<td>Market Category</td>
+<td>{{.Category}}</td>
+</tr>
+{{if $.ShareholdingDate}}<tr>
+<td>Share Holding Percentage [as on {{$.ShareholdingDate}}]</td>
+<td>
+<table class="table table-bordered" style="width:100%">
+<tr>
+<td>Sponsor/Director: {{fixed (index .Shareholding 0) 2}}</td>
+<td>Govt: {{fixed (index .Shareholding 1) 2}}</td>
+<td>Institute: {{fixed (index .Shareholding 2) 2}}</td>
+<td>Foreign: {{fixed (index .Shareholding 3) 2}}</td>
+<td>Public: {{fixed (index .Shareholding 4) 2}}</td>
+</tr>
+</table>
+</td>
+</tr>
+{{end}}</table>
+</div>
+{{end}}{{end}}</section>
+</div>
+</body>
+</html>
+`))
+
+var dseCompanyListingTemplate = template.Must(template.New("dse_company_listing").Parse(dseHeader + `<div class="container">
+<section class="body-section">
+<h2 class="BodyHead topBodyHead">Listed Companies</h2>
+<div class="BodyContent">
+{{range .Groups}}<div class="row">
+<div class="col-md-12">
+<h2 class="BodyHead">{{.Name}}</h2>
+{{range .Companies}}<a href="displayCompany.php?name={{.TradingCode}}" class="ab1">{{.TradingCode}}</a> <span style="color:#666">({{.Name}})</span><br>
+{{end}}</div>
+</div>
+{{end}}</div>
+</section>
+</div>
+</body>
+</html>
+`))
+
+var dseIndustryListingTemplate = template.Must(template.New("dse_industry_listing").Parse(dseHeader + `<div class="container">
+<section class="body-section">
+<h2 class="BodyHead topBodyHead">Listed Companies by Industry</h2>
+<div class="table-responsive">
+<table class="table table-bordered background-white">
+{{range .Industries}}<tr>
+<td><a href="companylistbyindustry.php?industryno={{.Number}}" class="ab1">{{.Name}}</a></td>
+<td>{{len .Companies}}</td>
+</tr>
+{{end}}</table>
+</div>
+</section>
+</div>
+</body>
+</html>
+`))
+
+var dseIndustryTemplate = template.Must(template.New("dse_industry").Parse(dseHeader + `<div class="container">
+<section class="body-section">
+<h2 class="BodyHead topBodyHead">{{.Industry.Name}}</h2>
+<div class="BodyContent">
+{{range .Industry.Companies}}<a href="displayCompany.php?name={{.TradingCode}}" class="ab1">{{.TradingCode}}</a> <span style="color:#666">({{.Name}})</span><br>
+{{end}}</div>
+</section>
+</div>
+</body>
+</html>
+`))
+
+var dsePriceEarningRatioTemplate = template.Must(template.New("dse_pe_ratio").Funcs(funcs).Parse(dseHeader + `<div class="container">
+<section class="body-section">
+<div class="row">
+<div class="col-md-12">
+<h2 class="BodyHead topBodyHead">Price Earnings Ratio</h2>
+</div>
+</div>
+<div class="table-responsive inner-scroll">
+<table class="table table-bordered background-white shares-table">
+<thead>
+<tr>
+<th>#</th>
+<th>Trade Code</th>
+<th>Close Price</th>
+<th>YCP</th>
+<th>P/E 1*</th>
+<th>P/E 2*</th>
+<th>P/E 3*</th>
+<th>P/E 4*</th>
+<th>P/E 5*</th>
+<th>P/E 6*</th>
+</tr>
+</thead>
+<tbody>
+{{range $i, $r := .Ratios}}<tr>
+<td>{{inc $i}}</td>
+<td><a href="displayCompany.php?name={{$r.TradingCode}}" class="ab1">{{$r.TradingCode}}</a></td>
+<td>{{number $r.ClosePrice}}</td>
+<td>{{number $r.YCP}}</td>
+{{range $r.PE}}<td>{{ratio .}}</td>
+{{end}}</tr>
+{{end}}</tbody>
+</table>
+</div>
+<p>* P/E 1 is based on the EPS of the last audited accounts and P/E 2, P/E 3 and P/E 4 on the annualized EPS of Q1, half year and Q3.</p>
+</section>
+</div>
+</body>
+</html>
+`))
+
+// dsePage holds the data of the dse page header
+type dsePage struct {
+	Title      string
+	Day        string
+	Status     string
+	LastUpdate string
+}
+
+// newDSEPage returns the header data of a dse page for the market
+func newDSEPage(m *Market, title string) dsePage {
+	t := dhakaTime(m.LastUpdate)
+	return dsePage{
+		Title:      title,
+		Day:        t.Format("Monday, January 2, 2006"),
+		Status:     status(m.Open),
+		LastUpdate: t.Format("Jan 2, 2006 at 3:04 PM"),
+	}
+}
+
+// indexRow is an index row of the dse and cse home pages
+type indexRow struct {
+	Name       string
+	Value      float64
+	Change     float64
+	Percentage float64
+}
+
+// dseShare is a row of a dse price table
+type dseShare struct {
+	*Share
+	Change     float64
+	Percentage float64
+}
+
+// newDSEShare returns the price table row of the share
+func newDSEShare(share *Share) *dseShare {
+	return &dseShare{
+		Share:      share,
+		Change:     math.Round((share.LTP-share.YCP)*100) / 100,
+		Percentage: percentage(share.LTP, share.YCP),
+	}
+}
+
+// moverTable is a top mover table of the dse home page
+type moverTable struct {
+	Heading string
+	Shares  []*dseShare
+}
+
+// dseMovers returns the top mover tables of the dse home page: the ten largest gainers and losers by the change in
+// percent rounded to 2 decimals and the twenty shares traded with the largest value, volume and number of trades.
+// Ties are sorted by trading code
+func dseMovers(shares []*Share) []moverTable {
+	// top returns the first n rows with a positive rank sorted by the rank in descending order
+	top := func(n int, rank func(s *dseShare) float64) []*dseShare {
+		rows := make([]*dseShare, 0)
+		for _, share := range shares {
+			if s := newDSEShare(share); rank(s) > 0 {
+				rows = append(rows, s)
+			}
+		}
+		sort.SliceStable(rows, func(i, j int) bool {
+			if ri, rj := rank(rows[i]), rank(rows[j]); ri != rj {
+				return ri > rj
+			}
+			return rows[i].TradingCode < rows[j].TradingCode
+		})
+		if len(rows) > n {
+			rows = rows[:n]
+		}
+		return rows
+	}
+	return []moverTable{
+		{"Top Ten Gainer", top(10, func(s *dseShare) float64 { return math.Round(s.Percentage*100) / 100 })},
+		{"Top Ten Loser", top(10, func(s *dseShare) float64 { return -math.Round(s.Percentage*100) / 100 })},
+		{"Top Twenty Shares by Value", top(20, func(s *dseShare) float64 { return s.ValueInMN })},
+		{"Top Twenty Shares by Volume", top(20, func(s *dseShare) float64 { return float64(s.Volume) })},
+		{"Top Twenty Shares by Trade", top(20, func(s *dseShare) float64 { return float64(s.Trade) })},
+	}
+}
+
+func (s *Server) handleDSEHome(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/dse/" {
+		http.NotFound(w, r)
+		return
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	m := s.market
+	data := struct {
+		dsePage
+		Indices                                         []indexRow
+		TotalTrade, TotalVolume                         int64
+		TotalValueInMN                                  float64
+		MarketCapInMN, EquityMarketCapInMN              float64
+		IssuesAdvanced, IssuesDeclined, IssuesUnchanged int
+		Movers                                          []moverTable
+	}{
+		dsePage:             newDSEPage(m, "Dhaka Stock Exchange"),
+		MarketCapInMN:       m.DSEMarketCapInMN,
+		EquityMarketCapInMN: m.DSEEquityMarketCapInMN,
+		Movers:              dseMovers(m.DSEShares),
+	}
+	for _, i := range []struct {
+		name  string
+		index Index
+	}{{"DSEX", m.DSEX}, {"DSES", m.DSES}, {"DS30", m.DS30}} {
+		data.Indices = append(data.Indices, indexRow{
+			Name:       i.name,
+			Value:      i.index.Value,
+			Change:     i.index.Change,
+			Percentage: percentage(i.index.Value, i.index.Value-i.index.Change),
+		})
+	}
+	for _, share := range m.DSEShares {
+		data.TotalTrade += share.Trade
+		data.TotalVolume += share.Volume
+		data.TotalValueInMN += share.ValueInMN
+		switch {
+		case share.LTP > share.YCP:
+			data.IssuesAdvanced++
+		case share.LTP < share.YCP:
+			data.IssuesDeclined++
+		default:
+			data.IssuesUnchanged++
+		}
+	}
+	s.render(w, dseHomeTemplate, data)
+}
+
+// renderDSEPrices renders a dse price table of the shares
+func (s *Server) renderDSEPrices(w http.ResponseWriter, heading string, shares []*Share, byPercentage bool) {
+	rows := make([]*dseShare, 0, len(shares))
+	for _, share := range shares {
+		rows = append(rows, newDSEShare(share))
+	}
+	if byPercentage {
+		sort.SliceStable(rows, func(i, j int) bool {
+			return rows[i].Percentage > rows[j].Percentage
+		})
+	}
+	data := struct {
+		dsePage
+		Heading    string
+		Percentage bool
+		Shares     []*dseShare
+	}{newDSEPage(s.market, "Latest Share Price"), heading, byPercentage, rows}
+	s.render(w, dsePricesTemplate, data)
+}
+
+func (s *Server) handleDSELatestPrices(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.renderDSEPrices(w, "Latest Share Price", s.market.DSEShares, false)
+}
+
+func (s *Server) handleDSELatestPricesByCategory(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	group := strings.ToUpper(r.URL.Query().Get("group"))
+	shares := make([]*Share, 0)
+	for _, share := range s.market.DSEShares {
+		if share.Category == group {
+			shares = append(shares, share)
+		}
+	}
+	s.renderDSEPrices(w, "Latest Share Price of Group "+group, shares, false)
+}
+
+func (s *Server) handleDSELatestPricesByChange(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.renderDSEPrices(w, "Latest Share Price by % Change", s.market.DSEShares, true)
+}
+
+func (s *Server) handleDSEDayEndArchive(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	q := r.URL.Query()
+	from, to, code := q.Get("startDate"), q.Get("endDate"), strings.ToUpper(q.Get("inst"))
+	prices := make([]*DayPrice, 0)
+	for _, p := range s.market.DSEArchive {
+		if p.Date >= from && p.Date <= to && (code == "" || code == "ALL INSTRUMENT" || p.TradingCode == code) {
+			prices = append(prices, p)
+		}
+	}
+	sort.SliceStable(prices, func(i, j int) bool {
+		return prices[i].Date > prices[j].Date
+	})
+	data := struct {
+		dsePage
+		Prices []*DayPrice
+	}{newDSEPage(s.market, "Day End Archive"), prices}
+	s.render(w, dseArchiveTemplate, data)
+}
+
+func (s *Server) handleDSECompany(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	code := strings.ToUpper(r.URL.Query().Get("name"))
+	company := &Company{}
+	for _, c := range s.market.DSECompanies {
+		if c.TradingCode == code {
+			company = c
+		}
+	}
+	date := func(layout, value string) string {
+		t, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return ""
+		}
+		return t.Format(layout)
+	}
+	data := struct {
+		dsePage
+		Company                       *Company
+		LastAGM, ShareholdingDate     string
+		CashDividends, StockDividends string
+	}{
+		dsePage:          newDSEPage(s.market, "Company Details"),
+		Company:          company,
+		LastAGM:          date("02-01-2006", company.LastAGM),
+		ShareholdingDate: date("Jan 2, 2006", company.ShareholdingDate),
+		CashDividends:    dividends(company.CashDividends),
+		StockDividends:   dividends(company.StockDividends),
+	}
+	s.render(w, dseCompanyTemplate, data)
+}
+
+// dividends returns the dividends the way the dse company page prints them, ex: "125% 2019, 130% 2018" or "-" for none
+func dividends(list []*Dividend) string {
+	if len(list) == 0 {
+		return "-"
+	}
+	printed := make([]string, len(list))
+	for i, d := range list {
+		printed[i] = number(d.Percentage) + "% " + strconv.Itoa(d.Year)
+	}
+	return strings.Join(printed, ", ")
+}
+
+// dseIndustry is an industry of the dse listing and the companies of the industry
+type dseIndustry struct {
+	Number    int
+	Name      string
+	Companies []*Company
+}
+
+// dseIndustries returns the industries of the companies sorted by name. They are numbered from 11200 like on the dse
+func dseIndustries(companies []*Company) []*dseIndustry {
+	industries := make([]*dseIndustry, 0)
+	for _, c := range companies {
+		var industry *dseIndustry
+		for _, i := range industries {
+			if i.Name == c.Industry {
+				industry = i
+			}
+		}
+		if industry == nil {
+			industry = &dseIndustry{Name: c.Industry}
+			industries = append(industries, industry)
+		}
+		industry.Companies = append(industry.Companies, c)
+	}
+	sort.SliceStable(industries, func(i, j int) bool {
+		return industries[i].Name < industries[j].Name
+	})
+	for i, industry := range industries {
+		industry.Number = 11200 + i
+	}
+	return industries
+}
+
+func (s *Server) handleDSECompanyListing(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	companies := append([]*Company(nil), s.market.DSECompanies...)
+	sort.SliceStable(companies, func(i, j int) bool {
+		return companies[i].TradingCode < companies[j].TradingCode
+	})
+	// the companies are grouped by the first letter of their trading code
+	groups := make([]*companyGroup, 0)
+	for _, c := range companies {
+		if c.TradingCode == "" {
+			continue
+		}
+		letter := c.TradingCode[:1]
+		if len(groups) == 0 || groups[len(groups)-1].Name != letter {
+			groups = append(groups, &companyGroup{Name: letter})
+		}
+		g := groups[len(groups)-1]
+		g.Companies = append(g.Companies, c)
+	}
+	data := struct {
+		dsePage
+		Groups []*companyGroup
+	}{newDSEPage(s.market, "Listed Companies"), groups}
+	s.render(w, dseCompanyListingTemplate, data)
+}
+
+func (s *Server) handleDSEIndustryListing(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data := struct {
+		dsePage
+		Industries []*dseIndustry
+	}{newDSEPage(s.market, "Listed Companies by Industry"), dseIndustries(s.market.DSECompanies)}
+	s.render(w, dseIndustryListingTemplate, data)
+}
+
+func (s *Server) handleDSEIndustry(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	number, _ := strconv.Atoi(r.URL.Query().Get("industryno"))
+	industry := &dseIndustry{}
+	for _, i := range dseIndustries(s.market.DSECompanies) {
+		if i.Number == number {
+			industry = i
+		}
+	}
+	data := struct {
+		dsePage
+		Industry *dseIndustry
+	}{newDSEPage(s.market, industry.Name), industry}
+	s.render(w, dseIndustryTemplate, data)
+}
+
+func (s *Server) handleDSEPriceEarningRatio(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data := struct {
+		dsePage
+		Ratios []*DSEPriceEarningRatio
+	}{newDSEPage(s.market, "Price Earnings Ratio"), s.market.DSEPriceEarningRatios[r.URL.Query().Get("date")]}
+	s.render(w, dsePriceEarningRatioTemplate, data)
+}
@@ -0,0 +1,82 @@
+package bdstockexchangetest
+
+import (
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// funcs are the functions the page templates format the numbers with, the way the exchange websites print them
+var funcs = template.FuncMap{
+	"number":  number,
+	"fixed":   fixed,
+	"integer": integer,
+	"ratio":   ratio,
+	"inc":     func(i int) int { return i + 1 },
+}
+
+// number returns the value with thousand separators and at most 4 decimals, ex: 1,105.3
+func number(v float64) string {
+	return commas(strconv.FormatFloat(math.Round(v*1e4)/1e4, 'f', -1, 64))
+}
+
+// fixed returns the value with thousand separators and prec decimals, ex: 4,987.47
+func fixed(v float64, prec int) string {
+	return commas(strconv.FormatFloat(v, 'f', prec, 64))
+}
+
+// integer returns the value with thousand separators, ex: 7,598,213
+func integer(v int64) string {
+	return commas(strconv.FormatInt(v, 10))
+}
+
+// ratio returns the value with 2 decimals or N/A for zero
+func ratio(v float64) string {
+	if v == 0 {
+		return "N/A"
+	}
+	return fixed(v, 2)
+}
+
+// commas puts thousand separators in the integer part of a formatted number
+func commas(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	frac := ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		s, frac = s[:i], s[i:]
+	}
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	return sign + b.String() + frac
+}
+
+// percentage returns the change of the value from the base in percent
+func percentage(value, base float64) float64 {
+	if base == 0 {
+		return 0
+	}
+	return (value - base) / base * 100
+}
+
+// status returns the market status text of the exchange websites
+func status(open bool) string {
+	if open {
+		return "Open"
+	}
+	return "Closed"
+}
+
+// dhakaTime returns the time in the Asia/Dhaka time zone the exchange websites print the times in
+func dhakaTime(t time.Time) time.Time {
+	return t.In(time.FixedZone("BST", 6*60*60))
+}
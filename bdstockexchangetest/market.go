@@ -0,0 +1,356 @@
+package bdstockexchangetest
+
+import (
+	"time"
+)
+
+// Share holds the latest price of a security as it is printed on the price pages of an exchange
+type Share struct {
+	TradingCode string
+	// Category is the dse group of the share, ex: A, B, G, N or Z
+	Category  string
+	LTP       float64
+	Open      float64
+	High      float64
+	Low       float64
+	CloseP    float64
+	YCP       float64
+	Trade     int64
+	ValueInMN float64
+	Volume    int64
+}
+
+// DayPrice holds a row of the dse day end archive
+type DayPrice struct {
+	// Date is the trading day in the 2006-01-02 format
+	Date string
+	Share
+}
+
+// Index holds the value of a dse index and its change from the last trading day
+type Index struct {
+	Value  float64
+	Change float64
+}
+
+// HighestRecord holds a highest trading record printed on the cse historical market page
+type HighestRecord struct {
+	Title string
+	Value float64
+	Date  string
+}
+
+// MarketDay holds the summary of a trading day printed on the cse historical market page
+type MarketDay struct {
+	Date          string
+	Trade         int64
+	Volume        int64
+	ValueInTK     float64
+	MarketCapInMN float64
+	CSE30         float64
+	CSCX          float64
+	CASPI         float64
+	CSE50         float64
+	CSI           float64
+}
+
+// Company holds a company printed on the listed companies and the company pages of an exchange.
+// The price statistics of the cse details page are taken from the cse share of the company
+type Company struct {
+	TradingCode string
+	Name        string
+	Industry    string
+	Category    string
+
+	ListingYear           int
+	AuthorizedCapitalInMN float64
+	PaidUpCapitalInMN     float64
+	FaceValue             float64
+	MarketLot             int64
+	TotalSecurities       int64
+	Week52Low             float64
+	Week52High            float64
+	// ShareholdingDate is the date of the shareholding in the 2006-01-02 format
+	ShareholdingDate string
+	// Shareholding is the percentage of the sponsor/director, govt, institute, foreign and public shareholders
+	Shareholding        [5]float64
+	FinancialHighlights []*FinancialHighlight
+
+	// ScripCode, DebutTradingDate, LastAGM and the dividends are only printed on the dse company page.
+	// The dates are in the 2006-01-02 format
+	ScripCode        string
+	DebutTradingDate string
+	LastAGM          string
+	CashDividends    []*Dividend
+	StockDividends   []*Dividend
+}
+
+// Dividend holds a dividend declared for a year in percent of the face value
+type Dividend struct {
+	Year       int
+	Percentage float64
+}
+
+// FinancialHighlight holds a year of the financial highlights printed on the cse company details page
+type FinancialHighlight struct {
+	Year          int
+	EPS           float64
+	NAVPerShare   float64
+	NetProfitInMN float64
+}
+
+// PriceEarningRatio holds a row of the cse price earning ratio page. A zero ratio is printed as N/A
+type PriceEarningRatio struct {
+	TradingCode       string
+	FinancialYearFrom string
+	FinancialYearTo   string
+	Quarter1          float64
+	HalfYear          float64
+	Quarter3          float64
+	AnnualizedEPS     float64
+	AuditedEPS        float64
+	ClosePrice        float64
+	PEAnnualized      float64
+	PEAudited         float64
+}
+
+// DSEPriceEarningRatio holds a row of the dse price earning ratio report. PE holds the ratios P/E 1 to P/E 6:
+// P/E 1 is based on the last audited accounts and P/E 2, 3 and 4 on the annualized eps of the first quarter,
+// the half year and the third quarter. A zero ratio is printed as N/A
+type DSEPriceEarningRatio struct {
+	TradingCode string
+	ClosePrice  float64
+	YCP         float64
+	PE          [6]float64
+}
+
+// WeeklyReport holds a cse weekly report. The PDF is served by the server under the File name,
+// or a pdf printing the Statistics if PDF is nil. A report without both is not found
+type WeeklyReport struct {
+	Date       string
+	Title      string
+	File       string
+	PDF        []byte
+	Statistics *WeeklyStatistics
+}
+
+// WeeklyStatistics holds the tables printed in a cse weekly report pdf. The changes are computed from the values
+type WeeklyStatistics struct {
+	Indices     []*WeeklyIndex
+	ThisWeek    WeeklyTotals
+	LastWeek    WeeklyTotals
+	TopGainers  []*WeeklyMover
+	TopLosers   []*WeeklyMover
+	TopTurnover []*WeeklyTurnover
+}
+
+// WeeklyIndex holds the value of a cse index at the end of the week and of the last week
+type WeeklyIndex struct {
+	Name     string
+	ThisWeek float64
+	LastWeek float64
+}
+
+// WeeklyTotals holds the totals of the trades of a week
+type WeeklyTotals struct {
+	Trade         int64
+	Volume        int64
+	ValueInMN     float64
+	MarketCapInMN float64
+}
+
+// WeeklyMover holds the close price of a share at the end of the week and of the last week
+type WeeklyMover struct {
+	TradingCode        string
+	ClosePrice         float64
+	LastWeekClosePrice float64
+}
+
+// WeeklyTurnover holds the weekly trades of a share
+type WeeklyTurnover struct {
+	TradingCode string
+	Volume      int64
+	ValueInMN   float64
+}
+
+// Market holds the data the server renders the exchange pages from.
+// The totals and the advanced, declined and unchanged issues of the dse home page are computed from DSEShares
+type Market struct {
+	// Open is the market status printed on the pages of both exchanges
+	Open bool
+	// LastUpdate is the time printed as the last update of the dse home page
+	LastUpdate time.Time
+
+	DSEShares []*Share
+	DSEX      Index
+	DSES      Index
+	DS30      Index
+	// DSEMarketCapInMN is the market capitalization of all the securities and DSEEquityMarketCapInMN of the equities
+	DSEMarketCapInMN       float64
+	DSEEquityMarketCapInMN float64
+	// DSEArchive is the dse day end archive. The rows of a range are served with the latest day first
+	DSEArchive []*DayPrice
+	// DSEPriceEarningRatios are the price earning ratios by date in the 2006-01-02 format.
+	// A date without ratios is served as a day the market was closed
+	DSEPriceEarningRatios map[string][]*DSEPriceEarningRatio
+	// DSECompanies are the companies of the dse listing and company pages. The Industry is the sector of the dse
+	DSECompanies []*Company
+
+	CSEShares         []*Share
+	CSEHighestRecords []*HighestRecord
+	// CSEHistory is the historical market summary with the latest day first
+	CSEHistory   []*MarketDay
+	CSECompanies []*Company
+	// CSEPriceEarningRatios are the price earning ratios by date in the 2006-01-02 format.
+	// A date without ratios is served as a day the market was closed
+	CSEPriceEarningRatios map[string][]*PriceEarningRatio
+	// CSEWeeklyReports are the weekly reports by year with the latest report first
+	CSEWeeklyReports map[int][]*WeeklyReport
+}
+
+// DefaultMarket returns a closed market with a few shares, companies and reports of 15 October 2020
+func DefaultMarket() *Market {
+	return &Market{
+		Open:       false,
+		LastUpdate: time.Date(2020, time.October, 15, 15, 10, 0, 0, time.FixedZone("BST", 6*60*60)),
+		DSEShares: []*Share{
+			{TradingCode: "ACI", Category: "A", LTP: 214.5, Open: 213.5, High: 217.9, Low: 212, CloseP: 214.7, YCP: 213.2, Trade: 1253, ValueInMN: 28.412, Volume: 132507},
+			{TradingCode: "BATBC", Category: "A", LTP: 1105.3, Open: 1102, High: 1112, Low: 1098.5, CloseP: 1104.9, YCP: 1101.6, Trade: 842, ValueInMN: 45.186, Volume: 40902},
+			{TradingCode: "BEXIMCO", Category: "A", LTP: 41.2, Open: 40.8, High: 42, Low: 40.1, CloseP: 41.3, YCP: 40.6, Trade: 5120, ValueInMN: 312.774, Volume: 7598213},
+			{TradingCode: "BRACBANK", Category: "A", LTP: 42.9, Open: 43.3, High: 43.5, Low: 42.5, CloseP: 42.9, YCP: 43.3, Trade: 1844, ValueInMN: 60.203, Volume: 1401233},
+			{TradingCode: "EMERALDOIL", Category: "Z", LTP: 13.1, Open: 13.5, High: 13.6, Low: 12.9, CloseP: 13.2, YCP: 13.5, Trade: 402, ValueInMN: 3.114, Volume: 236940},
+			{TradingCode: "GP", Category: "A", LTP: 322.4, Open: 321, High: 325, Low: 320.1, CloseP: 322.6, YCP: 320.8, Trade: 2011, ValueInMN: 118.902, Volume: 368711},
+			{TradingCode: "RENATA", Category: "A", LTP: 1155, Open: 1150, High: 1164.8, Low: 1150.2, CloseP: 1156.1, YCP: 1149.7, Trade: 301, ValueInMN: 12.055, Volume: 10437},
+			{TradingCode: "SQURPHARMA", Category: "A", LTP: 197.6, Open: 197, High: 199, Low: 196.1, CloseP: 197.5, YCP: 196.9, Trade: 2389, ValueInMN: 93.847, Volume: 475008},
+		},
+		DSEX: Index{Value: 4987.47, Change: 12.35},
+		DSES: Index{Value: 1132.14, Change: 3.1},
+		DS30: Index{Value: 1701.92, Change: -5.8},
+
+		DSEMarketCapInMN:       4012345.678,
+		DSEEquityMarketCapInMN: 3187654.321,
+		DSEArchive: []*DayPrice{
+			{Date: "2020-10-13", Share: Share{TradingCode: "GP", LTP: 321.9, Open: 323.1, High: 324, Low: 320.5, CloseP: 321.9, YCP: 323, Trade: 1602, ValueInMN: 88.034, Volume: 273018}},
+			{Date: "2020-10-14", Share: Share{TradingCode: "GP", LTP: 320.8, Open: 322, High: 323.5, Low: 319, CloseP: 320.8, YCP: 321.9, Trade: 1745, ValueInMN: 96.517, Volume: 300211}},
+			{Date: "2020-10-15", Share: Share{TradingCode: "GP", LTP: 322.4, Open: 321, High: 325, Low: 320.1, CloseP: 322.6, YCP: 320.8, Trade: 2011, ValueInMN: 118.902, Volume: 368711}},
+			{Date: "2020-10-15", Share: Share{TradingCode: "SQURPHARMA", LTP: 197.6, Open: 197, High: 199, Low: 196.1, CloseP: 197.5, YCP: 196.9, Trade: 2389, ValueInMN: 93.847, Volume: 475008}},
+		},
+		DSEPriceEarningRatios: map[string][]*DSEPriceEarningRatio{
+			"2020-10-15": {
+				{TradingCode: "ACI", ClosePrice: 214.7, YCP: 213.2},
+				{TradingCode: "BATBC", ClosePrice: 1104.9, YCP: 1101.6, PE: [6]float64{28.41, 26.73, 25.9, 0, 27.05}},
+				{TradingCode: "GP", ClosePrice: 322.6, YCP: 320.8, PE: [6]float64{12.76, 13.02, 12.55, 12.81, 12.7}},
+			},
+		},
+		DSECompanies: []*Company{
+			{TradingCode: "ACI", Name: "ACI Limited", Industry: "Pharmaceuticals & Chemicals", Category: "A"},
+			{TradingCode: "BATBC", Name: "British American Tobacco Bangladesh Company Limited", Industry: "Food & Allied", Category: "A"},
+			{TradingCode: "BEXIMCO", Name: "Bangladesh Export Import Company Ltd.", Industry: "Miscellaneous", Category: "A"},
+			{TradingCode: "BRACBANK", Name: "BRAC Bank Limited", Industry: "Bank", Category: "A"},
+			{TradingCode: "EMERALDOIL", Name: "Emerald Oil Industries Ltd.", Industry: "Food & Allied", Category: "Z"},
+			{
+				TradingCode: "GP", Name: "Grameenphone Ltd.", Industry: "Telecommunication", Category: "A",
+				ListingYear: 2009, AuthorizedCapitalInMN: 40000, PaidUpCapitalInMN: 13503, FaceValue: 10, MarketLot: 1, TotalSecurities: 1350300022,
+				ShareholdingDate: "2020-09-30", Shareholding: [5]float64{90, 0, 2.1, 5.61, 2.29},
+				FinancialHighlights: []*FinancialHighlight{
+					{Year: 2019, EPS: 25.52, NAVPerShare: 36.64, NetProfitInMN: 34593},
+					{Year: 2018, EPS: 25.57, NAVPerShare: 33.7, NetProfitInMN: 34525},
+				},
+				ScripCode: "22235", DebutTradingDate: "2009-11-16", LastAGM: "2020-04-21",
+				CashDividends: []*Dividend{{Year: 2019, Percentage: 125}, {Year: 2018, Percentage: 130}, {Year: 2017, Percentage: 280}},
+			},
+			{TradingCode: "RENATA", Name: "Renata Limited", Industry: "Pharmaceuticals & Chemicals", Category: "A"},
+			{TradingCode: "SQURPHARMA", Name: "Square Pharmaceuticals Ltd.", Industry: "Pharmaceuticals & Chemicals", Category: "A"},
+		},
+		CSEShares: []*Share{
+			{TradingCode: "ACI", LTP: 214, Open: 213, High: 216.5, Low: 212, CloseP: 214, YCP: 213.1, Trade: 87, ValueInMN: 1.402, Volume: 6551},
+			{TradingCode: "BATBC", LTP: 1104, Open: 1101, High: 1110, Low: 1099, CloseP: 1104, YCP: 1100.9, Trade: 35, ValueInMN: 2.216, Volume: 2007},
+			{TradingCode: "BEXIMCO", LTP: 41.1, Open: 40.7, High: 41.9, Low: 40.2, CloseP: 41.1, YCP: 40.6, Trade: 412, ValueInMN: 9.871, Volume: 240115},
+			{TradingCode: "BRACBANK", LTP: 43, Open: 43.2, High: 43.4, Low: 42.6, CloseP: 43, YCP: 43.3, Trade: 66, ValueInMN: 0.912, Volume: 21220},
+			{TradingCode: "GP", LTP: 322, Open: 321, High: 324.9, Low: 320.5, CloseP: 322, YCP: 320.9, Trade: 120, ValueInMN: 4.511, Volume: 14006},
+			{TradingCode: "SQURPHARMA", LTP: 197.2, Open: 197, High: 198.8, Low: 196, CloseP: 197.2, YCP: 196.8, Trade: 143, ValueInMN: 3.702, Volume: 18772},
+		},
+		CSEHighestRecords: []*HighestRecord{
+			{Title: "Highest Trade", Value: 201562, Date: "2011-01-12"},
+			{Title: "Highest Volume", Value: 112569321, Date: "2010-12-05"},
+			{Title: "Highest Value (mn)", Value: 3281.52, Date: "2010-12-05"},
+			{Title: "Highest Market Capitalization (mn)", Value: 4526108.09, Date: "2020-10-12"},
+		},
+		CSEHistory: []*MarketDay{
+			{Date: "2020-10-15", Trade: 17012, Volume: 52123009, ValueInTK: 1512345678.5, MarketCapInMN: 3901234.12, CSE30: 8712.31, CSCX: 8301.72, CASPI: 14256.09, CSE50: 1041.26, CSI: 948.66},
+			{Date: "2020-10-14", Trade: 16102, Volume: 49811203, ValueInTK: 1402117004.2, MarketCapInMN: 3889120.55, CSE30: 8690.11, CSCX: 8280.04, CASPI: 14210.87, CSE50: 1038.9, CSI: 946.12},
+			{Date: "2020-10-13", Trade: 15877, Volume: 47220915, ValueInTK: 1356002110, MarketCapInMN: 3870004.18, CSE30: 8650.42, CSCX: 8244.16, CASPI: 14150.21, CSE50: 1033.18, CSI: 941.08},
+		},
+		CSECompanies: []*Company{
+			{TradingCode: "ACI", Name: "ACI Limited", Industry: "Pharmaceuticals & Chemicals", Category: "A"},
+			{TradingCode: "BATBC", Name: "British American Tobacco Bangladesh Company Limited", Industry: "Food & Allied", Category: "A"},
+			{TradingCode: "BEXIMCO", Name: "Bangladesh Export Import Company Ltd.", Industry: "Miscellaneous", Category: "A"},
+			{TradingCode: "BRACBANK", Name: "BRAC Bank Limited", Industry: "Bank", Category: "A"},
+			{TradingCode: "EMERALDOIL", Name: "Emerald Oil Industries Ltd.", Industry: "Food & Allied", Category: "Z"},
+			{
+				TradingCode: "GP", Name: "Grameenphone Ltd.", Industry: "Telecommunication", Category: "A",
+				ListingYear: 2009, AuthorizedCapitalInMN: 40000, PaidUpCapitalInMN: 13503, FaceValue: 10, MarketLot: 1, TotalSecurities: 1350300022,
+				Week52Low: 240, Week52High: 390, ShareholdingDate: "2020-09-30", Shareholding: [5]float64{90, 0, 2.1, 5.61, 2.29},
+				FinancialHighlights: []*FinancialHighlight{
+					{Year: 2019, EPS: 25.52, NAVPerShare: 36.64, NetProfitInMN: 34593},
+					{Year: 2018, EPS: 25.57, NAVPerShare: 33.7, NetProfitInMN: 34525},
+				},
+			},
+			{TradingCode: "SQURPHARMA", Name: "Square Pharmaceuticals Ltd.", Industry: "Pharmaceuticals & Chemicals", Category: "A"},
+		},
+		CSEPriceEarningRatios: map[string][]*PriceEarningRatio{
+			"2020-10-14": {
+				{TradingCode: "ACI", FinancialYearFrom: "01-07-2019", FinancialYearTo: "30-06-2020", Quarter1: -1.05, HalfYear: -3.41, Quarter3: -5.21, AnnualizedEPS: -6.95, AuditedEPS: -2.47, ClosePrice: 213.1},
+				{TradingCode: "BATBC", FinancialYearFrom: "01-01-2020", FinancialYearTo: "31-12-2020", Quarter1: 12.77, HalfYear: 26.19, Quarter3: 40.02, AnnualizedEPS: 53.36, AuditedEPS: 57.38, ClosePrice: 1100.5, PEAnnualized: 20.62, PEAudited: 19.18},
+				{TradingCode: "GP", FinancialYearFrom: "01-01-2020", FinancialYearTo: "31-12-2020", Quarter1: 6.47, HalfYear: 12.56, Quarter3: 19.29, AnnualizedEPS: 25.72, AuditedEPS: 25.52, ClosePrice: 320.5, PEAnnualized: 12.46, PEAudited: 12.56},
+			},
+			"2020-10-15": {
+				{TradingCode: "ACI", FinancialYearFrom: "01-07-2019", FinancialYearTo: "30-06-2020", Quarter1: -1.05, HalfYear: -3.41, Quarter3: -5.21, AnnualizedEPS: -6.95, AuditedEPS: -2.47, ClosePrice: 214},
+				{TradingCode: "BATBC", FinancialYearFrom: "01-01-2020", FinancialYearTo: "31-12-2020", Quarter1: 12.77, HalfYear: 26.19, Quarter3: 40.02, AnnualizedEPS: 53.36, AuditedEPS: 57.38, ClosePrice: 1104, PEAnnualized: 20.69, PEAudited: 19.24},
+				{TradingCode: "GP", FinancialYearFrom: "01-01-2020", FinancialYearTo: "31-12-2020", Quarter1: 6.47, HalfYear: 12.56, Quarter3: 19.29, AnnualizedEPS: 25.72, AuditedEPS: 25.52, ClosePrice: 322, PEAnnualized: 12.52, PEAudited: 12.62},
+				{TradingCode: "SQURPHARMA", FinancialYearFrom: "01-07-2019", FinancialYearTo: "30-06-2020", Quarter1: 4.23, HalfYear: 8.65, Quarter3: 12.77, AnnualizedEPS: 17.03, AuditedEPS: 16.03, ClosePrice: 197.2, PEAnnualized: 11.58, PEAudited: 12.3},
+			},
+		},
+		CSEWeeklyReports: map[int][]*WeeklyReport{
+			2020: {
+				{
+					Date: "2020-10-15", Title: "Weekly Report 11 October to 15 October 2020", File: "weekly_report_20201015.pdf",
+					Statistics: &WeeklyStatistics{
+						Indices: []*WeeklyIndex{
+							{Name: "CASPI", ThisWeek: 14256.09, LastWeek: 14102.33},
+							{Name: "CSE30", ThisWeek: 8712.31, LastWeek: 8755.02},
+							{Name: "CSCX", ThisWeek: 8301.72, LastWeek: 8210.45},
+							{Name: "CSE50", ThisWeek: 1041.26, LastWeek: 1029.7},
+							{Name: "CSI", ThisWeek: 948.66, LastWeek: 951.3},
+						},
+						ThisWeek: WeeklyTotals{Trade: 80214, Volume: 243887120, ValueInMN: 7012.34, MarketCapInMN: 3901234.12},
+						LastWeek: WeeklyTotals{Trade: 75123, Volume: 231456789, ValueInMN: 6789.12, MarketCapInMN: 3862110.4},
+						TopGainers: []*WeeklyMover{
+							{TradingCode: "BEXIMCO", ClosePrice: 41.1, LastWeekClosePrice: 38.2},
+							{TradingCode: "GP", ClosePrice: 322, LastWeekClosePrice: 315.4},
+							{TradingCode: "SQURPHARMA", ClosePrice: 197.2, LastWeekClosePrice: 194.5},
+						},
+						TopLosers: []*WeeklyMover{
+							{TradingCode: "BRACBANK", ClosePrice: 43, LastWeekClosePrice: 44.8},
+							{TradingCode: "ACI", ClosePrice: 214, LastWeekClosePrice: 218.6},
+							{TradingCode: "BATBC", ClosePrice: 1104, LastWeekClosePrice: 1110.2},
+						},
+						TopTurnover: []*WeeklyTurnover{
+							{TradingCode: "BEXIMCO", Volume: 1212455, ValueInMN: 48.917},
+							{TradingCode: "GP", Volume: 70112, ValueInMN: 22.415},
+							{TradingCode: "SQURPHARMA", Volume: 93120, ValueInMN: 18.206},
+						},
+					},
+				},
+				{Date: "2020-10-08", Title: "Weekly Report 04 October to 08 October 2020", File: "weekly_report_20201008.pdf"},
+				{Date: "2020-10-01", Title: "Weekly Report 27 September to 01 October 2020", File: "weekly_report_20201001.pdf"},
+			},
+			2019: {
+				{Date: "2019-12-26", Title: "Weekly Report 22 December to 26 December 2019", File: "weekly_report_20191226.pdf"},
+			},
+			2018: {
+				{Date: "2018-12-27", Title: "Weekly Report 23 December to 27 December 2018", File: "weekly_report_20181227.pdf"},
+			},
+		},
+	}
+}
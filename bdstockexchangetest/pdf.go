@@ -0,0 +1,126 @@
+package bdstockexchangetest
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 50
+	pdfFontSize   = 9
+	pdfRowHeight  = 16
+)
+
+// pdfColumns are the x positions of the cells of a row. The first column is wide enough for the particulars
+var pdfColumns = []int{pdfMargin, 220, 310, 400, 490}
+
+// helveticaWidths are the widths of the characters from space to tilde of the Helvetica font in 1/1000 of the font size
+var helveticaWidths = []int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// weeklyReportPDF returns the pdf of the weekly report printing its statistics the way the cse prints them:
+// every table follows a heading and starts with a header row
+func weeklyReportPDF(r *WeeklyReport) []byte {
+	s := r.Statistics
+	rows := [][]string{
+		{"Chittagong Stock Exchange PLC."},
+		{r.Title},
+		{"Index Movement"},
+		{"Index", "This Week", "Last Week", "Change", "Change %"},
+	}
+	for _, i := range s.Indices {
+		rows = append(rows, []string{i.Name, fixed(i.ThisWeek, 2), fixed(i.LastWeek, 2), fixed(i.ThisWeek-i.LastWeek, 2), fixed(percentage(i.ThisWeek, i.LastWeek), 2)})
+	}
+	rows = append(rows,
+		[]string{"Market Turnover"},
+		[]string{"Particulars", "This Week", "Last Week", "Change %"},
+		[]string{"Number of Trade", integer(s.ThisWeek.Trade), integer(s.LastWeek.Trade), fixed(percentage(float64(s.ThisWeek.Trade), float64(s.LastWeek.Trade)), 2)},
+		[]string{"Volume", integer(s.ThisWeek.Volume), integer(s.LastWeek.Volume), fixed(percentage(float64(s.ThisWeek.Volume), float64(s.LastWeek.Volume)), 2)},
+		[]string{"Value (Tk. mn)", fixed(s.ThisWeek.ValueInMN, 2), fixed(s.LastWeek.ValueInMN, 2), fixed(percentage(s.ThisWeek.ValueInMN, s.LastWeek.ValueInMN), 2)},
+		[]string{"Market Capitalization (Tk. mn)", fixed(s.ThisWeek.MarketCapInMN, 2), fixed(s.LastWeek.MarketCapInMN, 2), fixed(percentage(s.ThisWeek.MarketCapInMN, s.LastWeek.MarketCapInMN), 2)},
+	)
+	for _, t := range []struct {
+		heading string
+		movers  []*WeeklyMover
+	}{{"Top 10 Gainers", s.TopGainers}, {"Top 10 Losers", s.TopLosers}} {
+		rows = append(rows, []string{t.heading}, []string{"Trading Code", "This Week Close", "Last Week Close", "Change %"})
+		for _, m := range t.movers {
+			rows = append(rows, []string{m.TradingCode, fixed(m.ClosePrice, 2), fixed(m.LastWeekClosePrice, 2), fixed(percentage(m.ClosePrice, m.LastWeekClosePrice), 2)})
+		}
+	}
+	rows = append(rows, []string{"Top 10 Turnover"}, []string{"Trading Code", "Volume", "Value (Tk. mn)"})
+	for _, t := range s.TopTurnover {
+		rows = append(rows, []string{t.TradingCode, integer(t.Volume), fixed(t.ValueInMN, 3)})
+	}
+	return writePDF(rows)
+}
+
+// writePDF returns a pdf printing the rows of cells in the Helvetica font, starting a new page when a page is full
+func writePDF(rows [][]string) []byte {
+	perPage := (pdfPageHeight - 2*pdfMargin) / pdfRowHeight
+	var contents []string
+	for len(rows) > 0 {
+		n := perPage
+		if n > len(rows) {
+			n = len(rows)
+		}
+		var c strings.Builder
+		for i, row := range rows[:n] {
+			y := pdfPageHeight - pdfMargin - i*pdfRowHeight
+			for j, cell := range row {
+				fmt.Fprintf(&c, "BT /F1 %d Tf %d %d Td (%s) Tj ET\n", pdfFontSize, pdfColumns[j], y, escapePDF(cell))
+			}
+		}
+		contents = append(contents, c.String())
+		rows = rows[n:]
+	}
+
+	// the objects are the catalog, the page tree, the font and a page and its content stream for every page
+	objects := []string{"<< /Type /Catalog /Pages 2 0 R >>", ""}
+	widths := make([]string, len(helveticaWidths))
+	for i, w := range helveticaWidths {
+		widths[i] = fmt.Sprint(w)
+	}
+	objects = append(objects, fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding "+
+		"/FirstChar 32 /LastChar 126 /Widths [%s] >>", strings.Join(widths, " ")))
+	kids := make([]string, len(contents))
+	for i, c := range contents {
+		page := len(objects) + 1
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+				pdfPageWidth, pdfPageHeight, page+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(c), c),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids))
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, o := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
+
+// escapePDF escapes the characters which end or escape a pdf string
+func escapePDF(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(s)
+}
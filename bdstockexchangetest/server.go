@@ -0,0 +1,142 @@
+// Package bdstockexchangetest provides a fake dse and cse website for testing code which uses the bdstockexchange package
+// without network access. The pages are rendered from a Market which the tests can change while the server runs,
+// ex: to move a price or to open and close the market
+package bdstockexchangetest
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/diptomondal007/bdstockexchange"
+)
+
+// Server is a httptest.Server serving the dse pages under /dse and the cse pages under /cse
+type Server struct {
+	// sessions counts the cse sessions started and sessionGeneration the expirations of their csrf tokens.
+	// They are first to be 64-bit aligned for the atomic operations
+	sessions          int64
+	sessionGeneration int64
+
+	*httptest.Server
+
+	mu     sync.RWMutex
+	market *Market
+}
+
+// NewServer starts and returns a new Server rendering the pages from the market. DefaultMarket is used if market is nil.
+// The caller should call Close when finished, to shut it down
+func NewServer(market *Market) *Server {
+	if market == nil {
+		market = DefaultMarket()
+	}
+	s := &Server{market: market}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/dse/", s.handleDSEHome)
+	mux.HandleFunc("/dse/latest_share_price_scroll_l.php", s.handleDSELatestPrices)
+	mux.HandleFunc("/dse/latest_share_price_all_group.php", s.handleDSELatestPricesByCategory)
+	mux.HandleFunc("/dse/latest_share_price_all_by_change.php", s.handleDSELatestPricesByChange)
+	mux.HandleFunc("/dse/day_end_archive.php", s.handleDSEDayEndArchive)
+	mux.HandleFunc("/dse/displayCompany.php", s.handleDSECompany)
+	mux.HandleFunc("/dse/pe_archive.php", s.handleDSEPriceEarningRatio)
+	mux.HandleFunc("/dse/company_listing.php", s.handleDSECompanyListing)
+	mux.HandleFunc("/dse/by_industrylisting.php", s.handleDSEIndustryListing)
+	mux.HandleFunc("/dse/companylistbyindustry.php", s.handleDSEIndustry)
+	mux.HandleFunc("/cse/", s.handleCSEHome)
+	mux.HandleFunc("/cse/market/current_price", s.handleCSECurrentPrice)
+	mux.HandleFunc("/cse/market/historical_market", s.handleCSEHistoricalMarket)
+	mux.HandleFunc("/cse/market/weekly_report", s.handleCSEWeeklyReport)
+	mux.HandleFunc("/cse/market/pe_ratio", s.handleCSEPriceEarningRatio)
+	mux.HandleFunc("/cse/company/listedcompanies", s.handleCSEListedCompanies)
+	mux.HandleFunc("/cse/company/companydetails/", s.handleCSECompanyDetails)
+	mux.HandleFunc("/cse/assets/weekly_report/", s.handleCSEWeeklyReportPDF)
+
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// DSEURL returns the base url of the fake dse website
+func (s *Server) DSEURL() string {
+	return s.URL + "/dse"
+}
+
+// CSEURL returns the base url of the fake cse website
+func (s *Server) CSEURL() string {
+	return s.URL + "/cse"
+}
+
+// DSE returns a DSE client configured with the options which fetches the pages from the server.
+// Its clock is the last update time of the market unless the options set another one
+func (s *Server) DSE(opts ...bdstockexchange.Option) *bdstockexchange.DSE {
+	opts = append([]bdstockexchange.Option{bdstockexchange.WithClock(s.now)}, opts...)
+	return bdstockexchange.NewDSE(append(opts, bdstockexchange.WithBaseURL(s.DSEURL()))...)
+}
+
+// CSE returns a CSE client configured with the options which fetches the pages from the server.
+// Its clock is the last update time of the market unless the options set another one
+func (s *Server) CSE(opts ...bdstockexchange.Option) *bdstockexchange.CSE {
+	opts = append([]bdstockexchange.Option{bdstockexchange.WithClock(s.now)}, opts...)
+	return bdstockexchange.NewCSE(append(opts, bdstockexchange.WithBaseURL(s.CSEURL()))...)
+}
+
+// now returns the last update time of the market
+func (s *Server) now() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.market.LastUpdate
+}
+
+// Update calls fn with the market of the server. The pages are not served while fn runs, so it can change the market freely
+func (s *Server) Update(fn func(m *Market)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.market)
+}
+
+// SetMarketOpen opens or closes the market of both exchanges and sets the last update time to now
+func (s *Server) SetMarketOpen(open bool) {
+	s.Update(func(m *Market) {
+		m.Open = open
+		m.LastUpdate = time.Now()
+	})
+}
+
+// SetPrice sets the last trade price and the close price of the share on both exchanges and widens the high and
+// low price of the day to it. It returns an error if neither exchange has a share with the trading code
+func (s *Server) SetPrice(code string, ltp float64) error {
+	found := false
+	s.Update(func(m *Market) {
+		for _, shares := range [][]*Share{m.DSEShares, m.CSEShares} {
+			for _, share := range shares {
+				if share.TradingCode != code {
+					continue
+				}
+				found = true
+				share.LTP = ltp
+				share.CloseP = ltp
+				if ltp > share.High {
+					share.High = ltp
+				}
+				if share.Low == 0 || ltp < share.Low {
+					share.Low = ltp
+				}
+			}
+		}
+	})
+	if !found {
+		return fmt.Errorf("bdstockexchangetest: no share with trading code %q", code)
+	}
+	return nil
+}
+
+// render writes the template executed with the data as a html page
+func (s *Server) render(w http.ResponseWriter, t *template.Template, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := t.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
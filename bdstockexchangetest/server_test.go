@@ -0,0 +1,440 @@
+package bdstockexchangetest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/diptomondal007/bdstockexchange"
+)
+
+func TestServer_DSE(t *testing.T) {
+	s := NewServer(nil)
+	defer s.Close()
+	dse := s.DSE()
+
+	prices, err := dse.GetLatestPrices(bdstockexchange.SortByTradingCode, bdstockexchange.ASC)
+	if err != nil {
+		t.Fatalf("GetLatestPrices() error = %v", err)
+	}
+	if len(prices) != 8 || prices[1].TradingCode != "BATBC" || prices[1].LTP.Float64() != 1105.3 || prices[1].Change.Float64() != 3.7 || prices[1].Volume != 40902 {
+		t.Errorf("GetLatestPrices() = %d shares, second %+v", len(prices), prices[1])
+	}
+
+	group, err := dse.GetLatestPricesByCategory("Z", bdstockexchange.SortByTradingCode, bdstockexchange.ASC)
+	if err != nil {
+		t.Fatalf("GetLatestPricesByCategory() error = %v", err)
+	}
+	if len(group) != 1 || group[0].TradingCode != "EMERALDOIL" {
+		t.Errorf("GetLatestPricesByCategory() = %+v, want EMERALDOIL only", group)
+	}
+
+	byChange, err := dse.GetLatestPricesSortedByPercentageChange()
+	if err != nil {
+		t.Fatalf("GetLatestPricesSortedByPercentageChange() error = %v", err)
+	}
+	if len(byChange) != 8 || byChange[0].TradingCode != "BEXIMCO" || byChange[0].PercentageChange != 1.48 {
+		t.Errorf("GetLatestPricesSortedByPercentageChange() first = %+v", byChange[0])
+	}
+
+	latest, err := dse.GetLatestPrices(bdstockexchange.SortByTradingCode, bdstockexchange.ASC)
+	if err != nil {
+		t.Fatalf("GetLatestPrices() error = %v", err)
+	}
+	gainers, err := dse.GetTopMovers(context.Background(), bdstockexchange.TopGainers, 10)
+	if err != nil {
+		t.Fatalf("GetTopMovers() error = %v", err)
+	}
+	if want, _ := bdstockexchange.TopMovers(latest, bdstockexchange.TopGainers, 10); !reflect.DeepEqual(gainers, want) {
+		t.Errorf("GetTopMovers() gainers = %+v, want %+v", gainers, want)
+	}
+	byValue, err := dse.GetTopMovers(context.Background(), bdstockexchange.TopByValue, 20)
+	if err != nil {
+		t.Fatalf("GetTopMovers() error = %v", err)
+	}
+	if want, _ := bdstockexchange.TopMovers(latest, bdstockexchange.TopByValue, 20); len(byValue) != 8 || !reflect.DeepEqual(byValue, want) {
+		t.Errorf("GetTopMovers() by value = %+v, want %+v", byValue, want)
+	}
+
+	summary, err := dse.GetMarketSummary()
+	if err != nil {
+		t.Fatalf("GetMarketSummary() error = %v", err)
+	}
+	if summary.DseX.DSEXIndex != 4987.47 || summary.Ds30.DS30IndexChange != -5.8 || summary.TotalTrade != 14162 ||
+		summary.TotalValueInMN.String() != "674.493" || summary.MarketCapInMN.String() != "4012345.678" ||
+		summary.IssuesAdvanced != 6 || summary.IssuesDeclined != 2 || summary.IssuesUnchanged != 0 {
+		t.Errorf("GetMarketSummary() = %+v", summary)
+	}
+
+	from := time.Date(2020, time.October, 1, 0, 0, 0, 0, bdstockexchange.Dhaka)
+	history, err := dse.GetHistoricalPrices(context.Background(), "GP", from, from.AddDate(0, 0, 14))
+	if err != nil {
+		t.Fatalf("GetHistoricalPrices() error = %v", err)
+	}
+	if len(history) != 3 || history[0].Date.Day() != 13 || history[2].Close.String() != "322.6" || history[2].Volume != 368711 {
+		t.Errorf("GetHistoricalPrices() = %d prices, first %+v", len(history), history[0])
+	}
+
+	companies, err := dse.GetAllListedCompanies()
+	if err != nil {
+		t.Fatalf("GetAllListedCompanies() error = %v", err)
+	}
+	if len(companies) != 8 || companies[6].TradingCode != "RENATA" || companies[6].CompanyName != "Renata Limited" {
+		t.Errorf("GetAllListedCompanies() = %d companies, seventh %+v", len(companies), companies[6])
+	}
+
+	industries, err := dse.GetAllListedCompaniesByIndustry()
+	if err != nil {
+		t.Fatalf("GetAllListedCompaniesByIndustry() error = %v", err)
+	}
+	if len(industries) != 5 || industries[3].IndustryType != "Pharmaceuticals & Chemicals" || len(industries[3].List) != 3 ||
+		industries[3].List[1].CompanyName != "Renata Limited" {
+		t.Errorf("GetAllListedCompaniesByIndustry() = %d industries, fourth %+v", len(industries), industries[3])
+	}
+
+	categories, err := dse.GetAllListedCompaniesByCategory()
+	if err != nil {
+		t.Fatalf("GetAllListedCompaniesByCategory() error = %v", err)
+	}
+	if len(categories) != 2 || categories[1].Category != "Z" || len(categories[1].List) != 1 || categories[1].List[0].CompanyName != "Emerald Oil Industries Ltd." {
+		t.Errorf("GetAllListedCompaniesByCategory() = %+v", categories)
+	}
+
+	day := time.Date(2020, time.October, 15, 0, 0, 0, 0, bdstockexchange.Dhaka)
+	pe, err := dse.GetPriceEarningRatio(context.Background(), day)
+	if err != nil {
+		t.Fatalf("GetPriceEarningRatio() error = %v", err)
+	}
+	if len(pe.PriceEarningRatioArray) != 3 || pe.PriceEarningRatioArray[1].TradingCode != "BATBC" || pe.PriceEarningRatioArray[1].ClosePrice.String() != "1104.9" ||
+		pe.PriceEarningRatioArray[1].PERatioBasedOnAnnualizedEPS != 25.9 || pe.PriceEarningRatioArray[2].PERatioBasedOnAnnualizedEPS != 12.81 ||
+		pe.PriceEarningRatioArray[0].PERatioBasedOnLastAuditedAccounts != 0 {
+		t.Errorf("GetPriceEarningRatio() = %+v", pe.PriceEarningRatioArray)
+	}
+	if _, err := dse.GetPriceEarningRatio(context.Background(), day.AddDate(0, 0, 1)); !errors.Is(err, bdstockexchange.ErrNoDataFound) {
+		t.Errorf("GetPriceEarningRatio() of a holiday error = %v, want ErrNoDataFound", err)
+	}
+
+	profile, err := dse.GetCompany(context.Background(), "gp")
+	if err != nil {
+		t.Fatalf("GetCompany() error = %v", err)
+	}
+	if profile.CompanyName != "Grameenphone Ltd." || profile.ScripCode != "22235" || profile.OutstandingSecurities != 1350300022 ||
+		profile.LastAGM.Format("2006-01-02") != "2020-04-21" || len(profile.CashDividends) != 3 || len(profile.StockDividends) != 0 ||
+		profile.Shareholding.Foreign.String() != "5.61" || len(profile.EPS) != 2 || profile.EPS[0].NAVPerShare.String() != "36.64" {
+		t.Errorf("GetCompany() = %+v", profile)
+	}
+	if _, err := dse.GetCompany(context.Background(), "NOTLISTED"); !errors.Is(err, bdstockexchange.ErrNoDataFound) {
+		t.Errorf("GetCompany() of a company not listed error = %v, want ErrNoDataFound", err)
+	}
+
+	if report := dse.Healthcheck(context.Background()); !report.Healthy() {
+		for _, e := range report.Endpoints {
+			t.Errorf("Healthcheck() endpoint %s error = %v", e.Name, e.Err)
+		}
+	}
+}
+
+func TestServer_CSE(t *testing.T) {
+	s := NewServer(nil)
+	defer s.Close()
+	cse := s.CSE()
+
+	prices, err := cse.GetLatestPrices(bdstockexchange.SortByTradingCode, bdstockexchange.ASC)
+	if err != nil {
+		t.Fatalf("GetLatestPrices() error = %v", err)
+	}
+	if len(prices) != 6 || prices[4].TradingCode != "GP" || prices[4].Open.Float64() != 321 || prices[4].Volume != 14006 {
+		t.Errorf("GetLatestPrices() = %d shares, fifth %+v", len(prices), prices[4])
+	}
+
+	summary, err := cse.GetMarketSummary()
+	if err != nil {
+		t.Fatalf("GetMarketSummary() error = %v", err)
+	}
+	if len(summary.HighestRecords) != 4 || len(summary.HistoricalSummaries) != 3 || summary.HistoricalSummaries[0].CASPI != 14256.09 {
+		t.Errorf("GetMarketSummary() = %+v", summary)
+	}
+
+	snapshot, err := cse.GetIndexSnapshot(context.Background())
+	if err != nil {
+		t.Fatalf("GetIndexSnapshot() error = %v", err)
+	}
+	if caspi := snapshot.Index("CASPI"); caspi == nil || caspi.Value != 14256.09 || caspi.Change != 45.22 ||
+		snapshot.IssuesAdvanced+snapshot.IssuesDeclined+snapshot.IssuesUnchanged != 6 {
+		t.Errorf("GetIndexSnapshot() = %+v", snapshot)
+	}
+
+	if report := cse.Healthcheck(context.Background()); !report.Healthy() {
+		for _, e := range report.Endpoints {
+			t.Errorf("Healthcheck() endpoint %s error = %v", e.Name, e.Err)
+		}
+	}
+
+	// the new year is not in the select of the weekly reports yet, so the reports of the year before are checked
+	january := DefaultMarket()
+	january.LastUpdate = time.Date(2021, time.January, 3, 15, 10, 0, 0, january.LastUpdate.Location())
+	js := NewServer(january)
+	defer js.Close()
+	for _, e := range js.CSE().Healthcheck(context.Background()).Endpoints {
+		if e.Name == "weekly_reports" && (!e.Healthy || e.Rows == 0) {
+			t.Errorf("Healthcheck() in January endpoint %s: rows = %d, error = %v", e.Name, e.Rows, e.Err)
+		}
+	}
+
+	companies, err := cse.GetAllListedCompanies()
+	if err != nil {
+		t.Fatalf("GetAllListedCompanies() error = %v", err)
+	}
+	if len(companies) != 7 || companies[3].TradingCode != "BRACBANK" || companies[3].CompanyName != "BRAC Bank Limited" {
+		t.Errorf("GetAllListedCompanies() = %d companies, fourth %+v", len(companies), companies[3])
+	}
+
+	industries, err := cse.GetAllListedCompaniesByIndustry()
+	if err != nil {
+		t.Fatalf("GetAllListedCompaniesByIndustry() error = %v", err)
+	}
+	if len(industries) != 5 || industries[0].IndustryType != "Pharmaceuticals & Chemicals" || len(industries[0].List) != 2 {
+		t.Errorf("GetAllListedCompaniesByIndustry() = %d industries, first %+v", len(industries), industries[0])
+	}
+
+	categories, err := cse.GetAllListedCompaniesByCategory()
+	if err != nil {
+		t.Fatalf("GetAllListedCompaniesByCategory() error = %v", err)
+	}
+	if len(categories) != 2 || categories[1].Category != "Z" || len(categories[1].List) != 1 {
+		t.Errorf("GetAllListedCompaniesByCategory() = %+v", categories)
+	}
+
+	reports, err := cse.GetAllWeeklyReports(2020)
+	if err != nil {
+		t.Fatalf("GetAllWeeklyReports() error = %v", err)
+	}
+	if len(reports.Reports) != 3 || reports.Reports[0].ReportPDFLink != s.CSEURL()+"/assets/weekly_report/weekly_report_20201015.pdf" {
+		t.Errorf("GetAllWeeklyReports() = %+v", reports.Reports)
+	}
+	if _, err := cse.GetAllWeeklyReports(2010); !errors.Is(err, bdstockexchange.ErrNotAValidYear) {
+		t.Errorf("GetAllWeeklyReports(2010) error = %v, want ErrNotAValidYear", err)
+	}
+
+	weekly, err := cse.GetWeeklyReport(reports.Reports[0].ReportPDFLink)
+	if err != nil {
+		t.Fatalf("GetWeeklyReport() error = %v", err)
+	}
+	if len(weekly.Indices) != 5 || weekly.Indices[0].Change != 153.76 || weekly.ThisWeek.Trade != 80214 || len(weekly.TopLosers) != 3 || weekly.TopLosers[0].ChangePercentage != -4.02 {
+		t.Errorf("GetWeeklyReport() = %+v", weekly)
+	}
+	if _, err := cse.GetWeeklyReport(reports.Reports[1].ReportPDFLink); !errors.Is(err, bdstockexchange.ErrHTTPStatus) {
+		t.Errorf("GetWeeklyReport() of a missing report error = %v, want ErrHTTPStatus", err)
+	}
+
+	pe, err := cse.GetPriceEarningRatio("15", "10", "2020")
+	if err != nil {
+		t.Fatalf("GetPriceEarningRatio() error = %v", err)
+	}
+	if len(pe.PriceEarningRatioArray) != 4 || pe.PriceEarningRatioArray[1].PERatioBasedOnAnnualizedEPS != 20.69 || pe.PriceEarningRatioArray[0].PERatioBasedOnAnnualizedEPS != 0 {
+		t.Errorf("GetPriceEarningRatio() = %+v", pe.PriceEarningRatioArray)
+	}
+
+	// the client fetches the form again for a new csrf token
+	s.ExpireSessions()
+	if _, err := cse.GetPriceEarningRatio("14", "10", "2020"); err != nil {
+		t.Errorf("GetPriceEarningRatio() after ExpireSessions() error = %v", err)
+	}
+	resp, err := http.PostForm(s.CSEURL()+"/market/pe_ratio", url.Values{"pe_date": {"2020-10-15"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != statusPageExpired {
+		t.Errorf("post without a csrf token status = %d, want %d", resp.StatusCode, statusPageExpired)
+	}
+	if _, err := cse.GetPriceEarningRatio("16", "10", "2020"); !errors.Is(err, bdstockexchange.ErrNoDataFound) {
+		t.Errorf("GetPriceEarningRatio() on a holiday error = %v, want ErrNoDataFound", err)
+	}
+
+	from := time.Date(2020, time.October, 11, 0, 0, 0, 0, bdstockexchange.Dhaka)
+	series, err := cse.GetPriceEarningRatioRange(context.Background(), from, from.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("GetPriceEarningRatioRange() error = %v", err)
+	}
+	if len(series) != 4 || series[2].TradingCode != "GP" || len(series[2].Points) != 2 || series[2].Points[1].Time.Day() != 15 {
+		t.Errorf("GetPriceEarningRatioRange() = %d series, third %+v", len(series), series[2])
+	}
+}
+
+func TestServer_SecurityMaster(t *testing.T) {
+	s := NewServer(nil)
+	defer s.Close()
+
+	m := &bdstockexchange.SecurityMaster{}
+	changes, err := m.Refresh(context.Background(), s.DSE(), s.CSE())
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if len(changes.Added) != 15 || len(m.Securities) != 8 {
+		t.Errorf("Refresh() added %d listings to %d securities, want 15 to 8", len(changes.Added), len(m.Securities))
+	}
+	if gp := m.Lookup(bdstockexchange.ExchangeDSE, "GP"); gp == nil || gp.CSE == nil || gp.DSE.Sector != "Telecommunication" || gp.DSE.Category != "A" {
+		t.Errorf("Lookup(DSE, GP) = %+v", gp)
+	}
+	if renata := m.Lookup(bdstockexchange.ExchangeDSE, "RENATA"); renata == nil || renata.CSE != nil {
+		t.Errorf("Lookup(DSE, RENATA) = %+v, want a security only listed on the dse", renata)
+	}
+
+	// a delisted company is neither listed nor traded
+	s.Update(func(m *Market) {
+		m.DSECompanies = m.DSECompanies[:len(m.DSECompanies)-1]
+		m.DSEShares = m.DSEShares[:len(m.DSEShares)-1]
+	})
+	changes, err = m.Refresh(context.Background(), s.DSE(), nil)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if len(changes.Removed) != 1 || changes.Removed[0].TradingCode != "SQURPHARMA" {
+		t.Errorf("Refresh() after a delisting removed %+v", changes.Removed)
+	}
+}
+
+func TestServer_SetMarketOpen(t *testing.T) {
+	s := NewServer(nil)
+	defer s.Close()
+
+	for _, open := range []bool{true, false} {
+		s.SetMarketOpen(open)
+		dseStatus, err := s.DSE().GetMarketStatus()
+		if err != nil {
+			t.Fatalf("DSE.GetMarketStatus() error = %v", err)
+		}
+		if dseStatus.IsOpen != open {
+			t.Errorf("DSE.GetMarketStatus() IsOpen = %v, want %v", dseStatus.IsOpen, open)
+		}
+		cseStatus, err := s.CSE().GetMarketStatus()
+		if err != nil {
+			t.Fatalf("CSE.GetMarketStatus() error = %v", err)
+		}
+		if cseStatus.IsOpen != open {
+			t.Errorf("CSE.GetMarketStatus() IsOpen = %v, want %v", cseStatus.IsOpen, open)
+		}
+	}
+}
+
+func TestServer_SetPrice(t *testing.T) {
+	s := NewServer(nil)
+	defer s.Close()
+
+	if err := s.SetPrice("GP", 330); err != nil {
+		t.Fatalf("SetPrice() error = %v", err)
+	}
+	if err := s.SetPrice("UNKNOWN", 1); err == nil {
+		t.Errorf("SetPrice() of an unknown share error = nil")
+	}
+
+	dse, err := s.DSE().GetLatestPrices(bdstockexchange.SortByLTP, bdstockexchange.DESC)
+	if err != nil {
+		t.Fatalf("DSE.GetLatestPrices() error = %v", err)
+	}
+	for _, share := range dse {
+		if share.TradingCode == "GP" && (share.LTP.Float64() != 330 || share.High.Float64() != 330 || share.Change.Float64() != 9.2) {
+			t.Errorf("DSE.GetLatestPrices() GP = %+v, want LTP and High 330", share)
+		}
+	}
+
+	cse, err := s.CSE().GetLatestPrices(bdstockexchange.SortByTradingCode, bdstockexchange.ASC)
+	if err != nil {
+		t.Fatalf("CSE.GetLatestPrices() error = %v", err)
+	}
+	for _, share := range cse {
+		if share.TradingCode == "GP" && (share.LTP.Float64() != 330 || share.High.Float64() != 330 || share.Low.Float64() != 320.5) {
+			t.Errorf("CSE.GetLatestPrices() GP = %+v, want LTP and High 330", share)
+		}
+	}
+
+	s.Update(func(m *Market) {
+		m.DSEShares = m.DSEShares[:1]
+	})
+	dse, err = s.DSE().GetLatestPrices(bdstockexchange.SortByTradingCode, bdstockexchange.ASC)
+	if err != nil {
+		t.Fatalf("DSE.GetLatestPrices() error = %v", err)
+	}
+	if len(dse) != 1 {
+		t.Errorf("DSE.GetLatestPrices() after Update = %d shares, want 1", len(dse))
+	}
+}
+
+// countingTransport counts the requests running at once
+type countingTransport struct {
+	running, max int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	n := atomic.AddInt32(&t.running, 1)
+	defer atomic.AddInt32(&t.running, -1)
+	for {
+		max := atomic.LoadInt32(&t.max)
+		if n <= max || atomic.CompareAndSwapInt32(&t.max, max, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestServer_GetAllCompanyDetails(t *testing.T) {
+	s := NewServer(nil)
+	defer s.Close()
+	transport := &countingTransport{}
+	cse := s.CSE(bdstockexchange.WithHTTPClient(&http.Client{Transport: transport}))
+
+	details, err := cse.GetAllCompanyDetails(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("GetAllCompanyDetails() error = %v", err)
+	}
+	if len(details) != len(s.market.CSECompanies) {
+		t.Fatalf("GetAllCompanyDetails() = %d companies, want %d", len(details), len(s.market.CSECompanies))
+	}
+	for i, c := range s.market.CSECompanies {
+		if details[i].TradingCode != c.TradingCode || details[i].CompanyName != c.Name {
+			t.Errorf("GetAllCompanyDetails()[%d] = %s, want %s", i, details[i].TradingCode, c.TradingCode)
+		}
+	}
+	if max := atomic.LoadInt32(&transport.max); max > 2 {
+		t.Errorf("GetAllCompanyDetails() ran %d requests at once, want at most 2", max)
+	}
+
+	gp, err := cse.GetCompanyDetails(context.Background(), "GP")
+	if err != nil {
+		t.Fatalf("GetCompanyDetails() error = %v", err)
+	}
+	if gp.PriceStats.LTP.String() != "322.00" || gp.Shareholding.Foreign.String() != "5.61" || len(gp.FinancialHighlights) != 2 {
+		t.Errorf("GetCompanyDetails() = %+v", gp)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := cse.GetAllCompanyDetails(ctx, 2); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetAllCompanyDetails() of a canceled context error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func Test_commas(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"0", "0"},
+		{"999", "999"},
+		{"1000", "1,000"},
+		{"-1234567.891", "-1,234,567.891"},
+		{"123456", "123,456"},
+	}
+	for _, tt := range tests {
+		if got := commas(tt.in); got != tt.want {
+			t.Errorf("commas(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package bdstockexchangetest
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+)
+
+// cseSessionCookie is the cookie the fake cse website keeps the session in
+const cseSessionCookie = "cse_session"
+
+// statusPageExpired is the status a form post with a missing or an expired csrf token is rejected with
+const statusPageExpired = 419
+
+// ExpireSessions expires the csrf tokens of every session, so the next form post of every client is rejected until
+// it fetches the form again
+func (s *Server) ExpireSessions() {
+	atomic.AddInt64(&s.sessionGeneration, 1)
+}
+
+// cseSession returns the csrf token of the session of the request. A new session is started with a cookie if the
+// request has none
+func (s *Server) cseSession(w http.ResponseWriter, r *http.Request) string {
+	if c, err := r.Cookie(cseSessionCookie); err == nil && c.Value != "" {
+		return s.csrfToken(c.Value)
+	}
+	id := strconv.FormatInt(atomic.AddInt64(&s.sessions, 1), 10)
+	http.SetCookie(w, &http.Cookie{Name: cseSessionCookie, Value: id, Path: "/", HttpOnly: true})
+	return s.csrfToken(id)
+}
+
+// checkCSRF reports whether a form post sends the current csrf token of its session. Any other request passes
+func (s *Server) checkCSRF(r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		return true
+	}
+	c, err := r.Cookie(cseSessionCookie)
+	return err == nil && r.PostFormValue("_token") == s.csrfToken(c.Value)
+}
+
+// csrfToken returns the csrf token of the session, which changes when the sessions are expired
+func (s *Server) csrfToken(session string) string {
+	sum := sha1.Sum([]byte(strconv.FormatInt(atomic.LoadInt64(&s.sessionGeneration), 10) + ":" + session))
+	return hex.EncodeToString(sum[:])[:20]
+}
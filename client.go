@@ -0,0 +1,271 @@
+package bdstockexchange
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	dseBaseURL = "https://www.dsebd.org"
+	cseBaseURL = "https://www.cse.com.bd"
+)
+
+// DefaultUserAgent is the User-Agent header sent with every request if none is set with WithUserAgent
+const DefaultUserAgent = "bdstockexchange (+https://github.com/diptomondal007/bdstockexchange)"
+
+// Option configures a DSE or CSE client
+type Option func(*client)
+
+// WithHTTPClient sets the http client used for every request. Use it to set timeouts, proxies or a custom transport
+func WithHTTPClient(c *http.Client) Option {
+	return func(cl *client) {
+		cl.httpClient = c
+	}
+}
+
+// WithBaseURL points the client to another host than the exchange website, ex: a local mirror or a test server
+func WithBaseURL(u string) Option {
+	return func(cl *client) {
+		cl.baseURL = strings.TrimRight(u, "/")
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request instead of DefaultUserAgent
+func WithUserAgent(ua string) Option {
+	return func(cl *client) {
+		cl.userAgent = ua
+	}
+}
+
+// WithCacheDir sets the directory the downloaded files, like the weekly report pdfs of the cse, are cached in under
+// the host and the path of their url. A cached file is read from the directory instead of being downloaded again.
+// Nothing is cached if it is not set
+func WithCacheDir(dir string) Option {
+	return func(cl *client) {
+		cl.cacheDir = dir
+	}
+}
+
+// WithClock sets the clock the current day is taken from, ex: by Healthcheck to look for the latest report.
+// Use it to run against a recorded or a fake market of another day. The current time is used if it is not set
+func WithClock(now func() time.Time) Option {
+	return func(cl *client) {
+		cl.clock = now
+	}
+}
+
+// client holds the configuration shared by every fetch of an exchange
+type client struct {
+	httpClient *http.Client
+	baseURL    string
+	userAgent  string
+	cacheDir   string
+	// session keeps the cookies and the form fields of the website. It is shared by the copies of the client
+	session *session
+	// clock returns the current time. time.Now is used if it is nil
+	clock func() time.Time
+}
+
+// newClient returns a client for the exchange hosted on defaultBaseURL with the options applied
+func newClient(defaultBaseURL string, opts []Option) client {
+	cl := client{baseURL: defaultBaseURL, session: newSession()}
+	for _, opt := range opts {
+		opt(&cl)
+	}
+	return cl
+}
+
+// now returns the current time in the Dhaka location
+func (cl *client) now() time.Time {
+	if cl.clock != nil {
+		return cl.clock().In(Dhaka)
+	}
+	return time.Now().In(Dhaka)
+}
+
+// http returns the configured http client or the default one
+func (cl *client) http() *http.Client {
+	if cl.httpClient == nil {
+		return http.DefaultClient
+	}
+	return cl.httpClient
+}
+
+// url joins the path with the configured base url or with defaultBaseURL if none is configured
+func (cl *client) url(defaultBaseURL, path string) string {
+	base := cl.baseURL
+	if base == "" {
+		base = defaultBaseURL
+	}
+	return base + path
+}
+
+// newRequest returns a new request for the url bound to the context
+func (cl *client) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	return http.NewRequestWithContext(ctx, method, url, body)
+}
+
+// send sends the request with the configured User-Agent and the cookies of the session and returns the response of
+// a 2xx status. The cookies the response sets are kept in the session. The caller must close the response body
+func (cl *client) send(req *http.Request) (*http.Response, error) {
+	ua := cl.userAgent
+	if ua == "" {
+		ua = DefaultUserAgent
+	}
+	req.Header.Set("User-Agent", ua)
+
+	// a http client with its own cookie jar keeps the cookies itself
+	jar := cl.http().Jar == nil && cl.session != nil
+	if jar {
+		for _, c := range cl.session.jar.Cookies(req.URL) {
+			req.AddCookie(c)
+		}
+	}
+	resp, err := cl.http().Do(req)
+	if err != nil {
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, &RequestError{URL: req.URL.String(), Err: err}
+	}
+	if jar {
+		if cookies := resp.Cookies(); len(cookies) > 0 {
+			cl.session.jar.SetCookies(req.URL, cookies)
+		}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, &HTTPStatusError{URL: req.URL.String(), StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	return resp, nil
+}
+
+// do sends the request with the configured User-Agent and parses the response body as html
+func (cl *client) do(req *http.Request) (*html.Node, error) {
+	resp, err := cl.send(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	r, err := charset.NewReader(resp.Body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, bodyError(req, err)
+	}
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, bodyError(req, err)
+	}
+	return doc, nil
+}
+
+// bodyError returns the error of reading the response body of the request as a *RequestError, or the context error
+// if the request was canceled
+func bodyError(req *http.Request, err error) error {
+	if ctxErr := req.Context().Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return &RequestError{URL: req.URL.String(), Err: err}
+}
+
+// loadURL fetches the url with a GET request and returns the parsed html document
+func (cl *client) loadURL(ctx context.Context, url string) (*html.Node, error) {
+	req, err := cl.newRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return cl.do(req)
+}
+
+// download fetches the url with a GET request and returns the response body, which must start with the magic bytes
+// of the file type so an error page is never returned or cached. The body is read from and saved to the cache
+// directory under the name if one is configured
+func (cl *client) download(ctx context.Context, url, name, magic string) ([]byte, error) {
+	var path string
+	if cl.cacheDir != "" {
+		path = filepath.Join(cl.cacheDir, name)
+		if b, err := ioutil.ReadFile(path); err == nil && strings.HasPrefix(string(b), magic) {
+			return b, nil
+		}
+	}
+
+	req, err := cl.newRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := cl.send(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, bodyError(req, err)
+	}
+	if !strings.HasPrefix(string(b), magic) {
+		return nil, errLayoutChanged(url, fmt.Sprintf("file starting with %q", magic))
+	}
+
+	if path != "" {
+		if err := writeFileAtomic(path, b); err != nil {
+			return nil, fmt.Errorf("cache %s: %w", url, err)
+		}
+	}
+	return b, nil
+}
+
+// cacheKey returns the file name a download of the url is cached under, the host and the full path of the url with the
+// query, ex: www.cse.com.bd/assets/weekly_report/weekly_report_20201015.pdf. The characters which are not safe in a
+// file name are replaced with _ and the path can not climb out of the cache directory
+func cacheKey(u *url.URL) string {
+	name := strings.ToLower(u.Host) + path.Clean("/"+u.Path)
+	if u.RawQuery != "" {
+		name += "_" + u.RawQuery
+	}
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_', r == '=', r == '/':
+			return r
+		}
+		return '_'
+	}, name)
+	return filepath.FromSlash(name)
+}
+
+// writeFileAtomic writes the file through a temporary file in the same directory, so a reader never sees a
+// partly written file
+func writeFileAtomic(path string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
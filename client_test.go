@@ -0,0 +1,96 @@
+package bdstockexchange
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_newClient(t *testing.T) {
+	httpClient := &http.Client{Timeout: time.Second}
+	type args struct {
+		defaultBaseURL string
+		opts           []Option
+	}
+	tests := []struct {
+		name string
+		args args
+		want client
+	}{
+		{"default", args{dseBaseURL, nil}, client{baseURL: dseBaseURL, session: newSession()}},
+		{"base url", args{dseBaseURL, []Option{WithBaseURL("http://127.0.0.1:8080/")}}, client{baseURL: "http://127.0.0.1:8080", session: newSession()}},
+		{"all", args{cseBaseURL, []Option{WithHTTPClient(httpClient), WithUserAgent("test")}}, client{httpClient: httpClient, baseURL: cseBaseURL, userAgent: "test", session: newSession()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newClient(tt.args.defaultBaseURL, tt.args.opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newClient() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_client_url(t *testing.T) {
+	tests := []struct {
+		name string
+		cl   client
+		path string
+		want string
+	}{
+		{"zero value", client{}, "/market/current_price", cseBaseURL + "/market/current_price"},
+		{"configured", client{baseURL: "http://localhost"}, "/", "http://localhost/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cl.url(cseBaseURL, tt.path); got != tt.want {
+				t.Errorf("client.url() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_cacheKey(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://www.CSE.com.bd/assets/weekly_report/weekly_report_20201015.pdf", "www.cse.com.bd/assets/weekly_report/weekly_report_20201015.pdf"},
+		{"https://www.cse.com.bd/assets/2019/weekly_report_20201015.pdf", "www.cse.com.bd/assets/2019/weekly_report_20201015.pdf"},
+		{"https://www.cse.com.bd/download.php?file=a b.pdf", "www.cse.com.bd/download.php_file=a_b.pdf"},
+		{"https://www.cse.com.bd/../../etc/passwd", "www.cse.com.bd/etc/passwd"},
+		{"http://127.0.0.1:8080/report.pdf", "127.0.0.1_8080/report.pdf"},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := cacheKey(u); got != filepath.FromSlash(tt.want) {
+			t.Errorf("cacheKey(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func Test_client_do_truncatedBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the connection is closed before the promised body is sent
+		body := "<html><body>" + strings.Repeat("<p>GP</p>", 500)
+		w.Header().Set("Content-Length", strconv.Itoa(2*len(body)))
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	if _, err := NewDSE(WithBaseURL(ts.URL)).GetMarketStatus(); !errors.Is(err, ErrNetwork) {
+		t.Errorf("GetMarketStatus() of a truncated page error = %v, want %v", err, ErrNetwork)
+	}
+	if _, err := NewCSE(WithBaseURL(ts.URL)).GetWeeklyReport("/weekly_report.pdf"); !errors.Is(err, ErrNetwork) {
+		t.Errorf("GetWeeklyReport() of a truncated pdf error = %v, want %v", err, ErrNetwork)
+	}
+}
@@ -0,0 +1,549 @@
+package bdstockexchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// CompanyProfile holds the profile and the fundamentals of a company printed on its dse company page
+type CompanyProfile struct {
+	CompanyName string `json:"company_name"`
+	TradingCode string `json:"trading_code"`
+	ScripCode   string `json:"scrip_code"`
+	Sector      string `json:"sector"`
+	// Category is the market category of the share, ex: A, B, G, N or Z
+	Category              string    `json:"category"`
+	ListingYear           int       `json:"listing_year"`
+	DebutTradingDate      time.Time `json:"debut_trading_date"`
+	AuthorizedCapitalInMN Decimal   `json:"authorized_capital"`
+	PaidUpCapitalInMN     Decimal   `json:"paid_up_capital"`
+	FaceValue             Decimal   `json:"face_value"`
+	MarketLot             int64     `json:"market_lot"`
+	OutstandingSecurities int64     `json:"outstanding_securities"`
+	// Shareholding is the latest shareholding composition printed on the page
+	Shareholding   Shareholding `json:"shareholding"`
+	LastAGM        time.Time    `json:"last_agm"`
+	CashDividends  []*Dividend  `json:"cash_dividends"`
+	StockDividends []*Dividend  `json:"stock_dividends"`
+	EPS            []*AnnualEPS `json:"eps"`
+}
+
+// Shareholding holds the percentage of the shares held by each group of shareholders on a date
+type Shareholding struct {
+	Date            time.Time `json:"date"`
+	SponsorDirector Decimal   `json:"sponsor_director"`
+	Government      Decimal   `json:"government"`
+	Institute       Decimal   `json:"institute"`
+	Foreign         Decimal   `json:"foreign"`
+	Public          Decimal   `json:"public"`
+}
+
+// Dividend is a dividend declared for a year in percent of the face value
+type Dividend struct {
+	Year       int     `json:"year"`
+	Percentage Decimal `json:"percentage"`
+}
+
+// AnnualEPS is the earning per share and the net asset value per share of a year of the audited financial statements
+type AnnualEPS struct {
+	Year        int     `json:"year"`
+	EPS         Decimal `json:"eps"`
+	NAVPerShare Decimal `json:"nav_per_share"`
+}
+
+// companyEPSColumns are the columns of the financial performance table of the dse company page
+var companyEPSColumns = []column{
+	{"year", []string{"YEAR"}},
+	{"eps", []string{"EPS", "EPS (BASIC)", "EPS - ORIGINAL"}},
+	{"nav", []string{"NAV PER SHARE", "NAV"}},
+}
+
+var (
+	// shareholdingPattern matches a group and its percentage, ex: "Sponsor/Director: 90.00"
+	shareholdingPattern = regexp.MustCompile(`(?i)(sponsor/director|govt|government|institute|foreign|public)\s*:\s*(-?[0-9.,]+)`)
+	// dividendPattern matches a dividend and its year, ex: "125% 2019" or "10%(2010)"
+	dividendPattern = regexp.MustCompile(`([0-9.]+)\s*%\s*\(?\s*(\d{4})`)
+	// asOnPattern matches the date of the shareholding label, ex: "[as on Sep 30, 2020]"
+	asOnPattern = regexp.MustCompile(`(?i)as on\s*([^\]]+)`)
+)
+
+// labels holds the values of the labelled cells of a page by the normalized label in the order they are printed
+type labels struct {
+	keys   []string
+	values map[string]string
+}
+
+// readLabels reads every table cell printed as a label. A label is either followed by its value in the same cell,
+// ex: "Trading Code: GP", or in the next cell of the row, ex: "Market Lot" and "1". The first value of a label is kept
+func readLabels(doc *html.Node) *labels {
+	l := &labels{values: make(map[string]string)}
+	add := func(label, value string) {
+		key := normalizeHeader(strings.TrimSuffix(strings.TrimSpace(label), ":"))
+		if key == "" {
+			return
+		}
+		if _, ok := l.values[key]; !ok {
+			l.keys = append(l.keys, key)
+			l.values[key] = value
+		}
+	}
+	for _, tr := range htmlquery.Find(doc, "//tr") {
+		cells := make([]*html.Node, 0)
+		for c := tr.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && (c.Data == "th" || c.Data == "td") {
+				cells = append(cells, c)
+			}
+		}
+		for i := 0; i < len(cells); i++ {
+			text := cleanText(htmlquery.InnerText(cells[i]))
+			colon := strings.Index(text, ":")
+			switch {
+			case colon >= 0 && colon < len(text)-1:
+				add(text[:colon], strings.TrimSpace(text[colon+1:]))
+			case colon == len(text)-1 && (i+1 == len(cells) || strings.Contains(htmlquery.InnerText(cells[i+1]), ":")):
+				add(text, "")
+			case i+1 < len(cells):
+				add(text, cleanText(htmlquery.InnerText(cells[i+1])))
+				i++
+			}
+		}
+	}
+	return l
+}
+
+// get returns the value of the first of the labels found on the page
+func (l *labels) get(names ...string) (string, bool) {
+	for _, name := range names {
+		if v, ok := l.values[normalizeHeader(name)]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// prefixed returns the first label starting with the prefix and its value
+func (l *labels) prefixed(prefix string) (string, string, bool) {
+	prefix = normalizeHeader(prefix)
+	for _, key := range l.keys {
+		if strings.HasPrefix(key, prefix) {
+			return key, l.values[key], true
+		}
+	}
+	return "", "", false
+}
+
+// cleanText returns the text with the repeated spaces and new lines replaced by a single space
+func cleanText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// GetCompany returns the profile of the company with the trading code from its dse company page. It returns an error
+// wrapping ErrInvalidArgument for an empty trading code and ErrNoDataFound if the dse does not list the company
+func (d *DSE) GetCompany(ctx context.Context, tradingCode string) (*CompanyProfile, error) {
+	code := strings.ToUpper(strings.TrimSpace(tradingCode))
+	if code == "" {
+		return nil, fmt.Errorf("%w: empty trading code", ErrInvalidArgument)
+	}
+
+	url := d.url("/displayCompany.php?name=" + url.QueryEscape(code))
+	doc, err := d.client.loadURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	l := readLabels(doc)
+	listedCode, ok := l.get("Trading Code")
+	if !ok {
+		return nil, errLayoutChanged(url, "trading code")
+	}
+	if listedCode == "" {
+		return nil, fmt.Errorf("%w: the dse does not list %s", ErrNoDataFound, code)
+	}
+
+	profile := &CompanyProfile{TradingCode: listedCode}
+	if h := htmlquery.FindOne(doc, `//h2[contains(., "Company Name")]`); h != nil {
+		text := cleanText(htmlquery.InnerText(h))
+		profile.CompanyName = strings.TrimSpace(text[strings.Index(text, ":")+1:])
+	}
+
+	p := &cellParser{url: url}
+	text := func(names ...string) string {
+		v, ok := l.get(names...)
+		if !ok && p.err == nil {
+			p.err = errLayoutChanged(url, fmt.Sprintf("label %q", names[0]))
+		}
+		return v
+	}
+	profile.ScripCode, _ = l.get("Scrip Code")
+	profile.Sector = text("Sector")
+	profile.Category = text("Market Category", "Category")
+	profile.AuthorizedCapitalInMN = p.decimal("authorized_capital", text("Authorized Capital (mn)", "Authorized Capital"))
+	profile.PaidUpCapitalInMN = p.decimal("paid_up_capital", text("Paid-up Capital (mn)", "Paid up Capital (mn)", "Paid-up Capital"))
+	profile.FaceValue = p.decimal("face_value", text("Face/par Value", "Face Value"))
+	profile.MarketLot = p.int64("market_lot", text("Market Lot"))
+	profile.OutstandingSecurities = p.int64("outstanding_securities", text("Total No. of Outstanding Securities", "Total No. of Securities"))
+	if year := text("Listing Year"); year != "" {
+		profile.ListingYear = p.int("listing_year", year)
+	}
+	if debut, ok := l.get("Debut Trading Date"); ok {
+		profile.DebutTradingDate = p.date("debut_trading_date", debut, dateLayouts...)
+	}
+	profile.LastAGM = p.date("last_agm", text("Last AGM held on", "Last AGM held"), dateLayouts...)
+	profile.CashDividends = p.dividends("cash_dividend", text("Cash Dividend"))
+	profile.StockDividends = p.dividends("stock_dividend", text("Bonus Issue (Stock Dividend)", "Stock Dividend"))
+	if label, value, ok := l.prefixed("Share Holding Percentage"); ok {
+		profile.Shareholding = p.shareholding(label, value)
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	profile.EPS, err = readAnnualEPS(doc, url)
+	if err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// dividends parses the dividends of the text, ex: "125% 2019, 130% 2018". A text without dividend like "-" is empty
+func (p *cellParser) dividends(column, text string) []*Dividend {
+	dividends := make([]*Dividend, 0)
+	for _, m := range dividendPattern.FindAllStringSubmatch(text, -1) {
+		dividends = append(dividends, &Dividend{
+			Year:       p.int(column, m[2]),
+			Percentage: p.decimal(column, m[1]),
+		})
+	}
+	return dividends
+}
+
+// shareholding parses the shareholding composition of the value and its date of the label,
+// ex: "SHARE HOLDING PERCENTAGE [AS ON SEP 30, 2020]" and "Sponsor/Director: 90.00 Govt: 0.00 ..."
+func (p *cellParser) shareholding(label, value string) Shareholding {
+	var s Shareholding
+	if m := asOnPattern.FindStringSubmatch(label); m != nil {
+		s.Date = p.date("shareholding_date", strings.Title(strings.ToLower(m[1])), dateLayouts...)
+	}
+	for _, m := range shareholdingPattern.FindAllStringSubmatch(value, -1) {
+		v := p.decimal("shareholding", m[2])
+		switch strings.ToLower(m[1]) {
+		case "sponsor/director":
+			s.SponsorDirector = v
+		case "govt", "government":
+			s.Government = v
+		case "institute":
+			s.Institute = v
+		case "foreign":
+			s.Foreign = v
+		case "public":
+			s.Public = v
+		}
+	}
+	return s
+}
+
+// readAnnualEPS returns the rows of the financial performance table of the dse company page, the latest year first
+func readAnnualEPS(doc *html.Node, url string) ([]*AnnualEPS, error) {
+	t, err := findTable(doc, url, companyEPSColumns)
+	if err != nil {
+		return nil, err
+	}
+	eps := make([]*AnnualEPS, 0, len(t.rows))
+	p := &cellParser{url: url}
+	for row, cells := range t.rows {
+		p.row = row
+		e := &AnnualEPS{
+			Year:        p.int("year", t.text(cells, "year")),
+			EPS:         p.decimal("eps", t.text(cells, "eps")),
+			NAVPerShare: p.decimal("nav", t.text(cells, "nav")),
+		}
+		if p.err != nil {
+			return nil, p.err
+		}
+		eps = append(eps, e)
+	}
+	return eps, nil
+}
+
+// defaultCompanyDetailsWorkers is the number of cse company details pages GetAllCompanyDetails fetches at once by default
+const defaultCompanyDetailsWorkers = 4
+
+// CompanyDetails holds the details of a company printed on its cse company details page
+type CompanyDetails struct {
+	CompanyName           string
+	TradingCode           string
+	Sector                string
+	Category              string
+	ListingYear           int
+	AuthorizedCapitalInMN Decimal
+	PaidUpCapitalInMN     Decimal
+	FaceValue             Decimal
+	MarketLot             int64
+	TotalSecurities       int64
+	Shareholding          Shareholding
+	// FinancialHighlights are the audited results of the company, the latest year first
+	FinancialHighlights []*FinancialHighlight
+	PriceStats          PriceStats
+}
+
+// FinancialHighlight holds the audited results of a year of a company
+type FinancialHighlight struct {
+	Year          int
+	EPS           Decimal
+	NAVPerShare   Decimal
+	NetProfitInMN Decimal
+}
+
+// PriceStats holds the recent price statistics of a share printed on its cse company details page
+type PriceStats struct {
+	LTP           Decimal
+	YCP           Decimal
+	DayLow        Decimal
+	DayHigh       Decimal
+	Week52Low     Decimal
+	Week52High    Decimal
+	Volume        int64
+	ValueInMN     Decimal
+	MarketCapInMN Decimal
+}
+
+// cseShareholdingColumns are the columns of the shareholding table of the cse company details page
+var cseShareholdingColumns = []column{
+	{"sponsor_director", []string{"SPONSOR/DIRECTOR", "SPONSOR"}},
+	{"government", []string{"GOVT", "GOVERNMENT"}},
+	{"institute", []string{"INSTITUTE"}},
+	{"foreign", []string{"FOREIGN"}},
+	{"public", []string{"PUBLIC"}},
+}
+
+// cseHighlightColumns are the columns of the financial highlights table of the cse company details page
+var cseHighlightColumns = []column{
+	{"year", []string{"YEAR"}},
+	{"eps", []string{"EPS"}},
+	{"nav", []string{"NAV PER SHARE", "NAV"}},
+	{"net_profit", []string{"NET PROFIT (MN)", "NET PROFIT"}},
+}
+
+// cseCompanyCode returns the trading code of a cse company details link, ex: https://www.cse.com.bd/company/companydetails/GP
+func cseCompanyCode(href string) string {
+	href = strings.TrimRight(strings.TrimSpace(href), "/")
+	return href[strings.LastIndex(href, "/")+1:]
+}
+
+// GetCompanyDetails returns the details of the company with the trading code from its cse company details page.
+// It returns an error wrapping ErrInvalidArgument for an empty trading code and ErrNoDataFound if the cse does not list the company
+func (c *CSE) GetCompanyDetails(ctx context.Context, code string) (*CompanyDetails, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return nil, fmt.Errorf("%w: empty trading code", ErrInvalidArgument)
+	}
+
+	url := c.url("/company/companydetails/" + url.PathEscape(code))
+	doc, err := c.client.loadURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	l := readLabels(doc)
+	listedCode, ok := l.get("Stock Code", "Trading Code")
+	if !ok {
+		return nil, errLayoutChanged(url, "stock code")
+	}
+	if listedCode == "" {
+		return nil, fmt.Errorf("%w: the cse does not list %s", ErrNoDataFound, code)
+	}
+
+	p := &cellParser{url: url}
+	text := func(names ...string) string {
+		v, ok := l.get(names...)
+		if !ok && p.err == nil {
+			p.err = errLayoutChanged(url, fmt.Sprintf("label %q", names[0]))
+		}
+		return v
+	}
+	details := &CompanyDetails{
+		CompanyName:           text("Company Name"),
+		TradingCode:           listedCode,
+		Sector:                text("Sector", "Industry"),
+		Category:              text("Category", "Market Category"),
+		ListingYear:           p.int("listing_year", text("Listing Year")),
+		AuthorizedCapitalInMN: p.decimal("authorized_capital", text("Authorized Capital (mn)", "Authorized Capital")),
+		PaidUpCapitalInMN:     p.decimal("paid_up_capital", text("Paid Up Capital (mn)", "Paid-up Capital (mn)", "Paid Up Capital")),
+		FaceValue:             p.decimal("face_value", text("Face Value")),
+		MarketLot:             p.int64("market_lot", text("Market Lot")),
+		TotalSecurities:       p.int64("total_securities", text("Total Securities", "Total No. of Securities")),
+	}
+	stats := &details.PriceStats
+	stats.LTP = p.decimal("ltp", text("Last Trade Price", "LTP"))
+	stats.YCP = p.decimal("ycp", text("Yesterday Closing Price", "YCP"))
+	stats.DayLow, stats.DayHigh = p.decimalRange("days_range", text("Day's Range", "Days Range"))
+	stats.Week52Low, stats.Week52High = p.decimalRange("52_weeks_range", text("52 Weeks Range", "52 Week Range"))
+	stats.Volume = p.int64("volume", text("Volume"))
+	stats.ValueInMN = p.decimal("value", text("Value (mn)", "Value"))
+	stats.MarketCapInMN = p.decimal("market_cap", text("Market Capitalization (mn)", "Market Capitalization"))
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	if details.Shareholding, err = readCSEShareholding(doc, url); err != nil {
+		return nil, err
+	}
+	if details.FinancialHighlights, err = readCSEHighlights(doc, url); err != nil {
+		return nil, err
+	}
+	return details, nil
+}
+
+// GetAllCompanyDetails returns the details of every company of GetAllListedCompanies in the same order.
+// At most workers details pages are fetched at once, 4 if workers is not positive. The companies the cse has
+// no details for and the companies with an empty trading code or a details page which can not be parsed are skipped,
+// unless no company could be parsed at all. It returns the first other error, ex: a network error, and cancels the
+// requests which are still running
+func (c *CSE) GetAllCompanyDetails(ctx context.Context, workers int) ([]*CompanyDetails, error) {
+	companies, err := c.GetAllListedCompaniesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if workers <= 0 {
+		workers = defaultCompanyDetailsWorkers
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg        sync.WaitGroup
+		once      sync.Once
+		firstErr  error
+		skipOnce  sync.Once
+		skipError error
+	)
+	details := make([]*CompanyDetails, len(companies))
+	jobs := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				d, err := c.GetCompanyDetails(ctx, companies[i].TradingCode)
+				if errors.Is(err, ErrNoDataFound) {
+					continue
+				}
+				if errors.Is(err, ErrLayoutChanged) || errors.Is(err, ErrInvalidArgument) {
+					skipOnce.Do(func() {
+						skipError = err
+					})
+					continue
+				}
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				details[i] = d
+			}
+		}()
+	}
+feed:
+	for i := range companies {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	result := make([]*CompanyDetails, 0, len(details))
+	for _, d := range details {
+		if d != nil {
+			result = append(result, d)
+		}
+	}
+	if len(result) == 0 && skipError != nil {
+		return nil, skipError
+	}
+	return result, nil
+}
+
+// decimalRange parses a range of two decimals, ex: "320.50 - 324.90". A range without value like "-" or "N/A", ex: of
+// a company which did not trade, is a range of 0 to 0
+func (p *cellParser) decimalRange(column, text string) (Decimal, Decimal) {
+	text = strings.TrimSpace(text)
+	if text == "--" || text == "N/A" || text == "-" {
+		return Decimal{}, Decimal{}
+	}
+	parts := strings.SplitN(text, " - ", 2)
+	if len(parts) != 2 {
+		p.fail(column, text, errors.New("not a range"))
+		return Decimal{}, Decimal{}
+	}
+	return p.decimal(column, parts[0]), p.decimal(column, parts[1])
+}
+
+// readCSEShareholding returns the shareholding table of the cse company details page and its date of the heading
+func readCSEShareholding(doc *html.Node, url string) (Shareholding, error) {
+	var s Shareholding
+	t, err := findTable(doc, url, cseShareholdingColumns)
+	if err != nil {
+		return s, err
+	}
+	if len(t.rows) == 0 {
+		return s, nil
+	}
+	p := &cellParser{url: url}
+	row := t.rows[0]
+	if h := htmlquery.FindOne(doc, `//h2[contains(., "Shareholding")]`); h != nil {
+		if m := asOnPattern.FindStringSubmatch(cleanText(htmlquery.InnerText(h))); m != nil {
+			s.Date = p.date("shareholding_date", m[1], dateLayouts...)
+		}
+	}
+	s.SponsorDirector = p.decimal("sponsor_director", t.text(row, "sponsor_director"))
+	s.Government = p.decimal("government", t.text(row, "government"))
+	s.Institute = p.decimal("institute", t.text(row, "institute"))
+	s.Foreign = p.decimal("foreign", t.text(row, "foreign"))
+	s.Public = p.decimal("public", t.text(row, "public"))
+	return s, p.err
+}
+
+// readCSEHighlights returns the rows of the financial highlights table of the cse company details page
+func readCSEHighlights(doc *html.Node, url string) ([]*FinancialHighlight, error) {
+	t, err := findTable(doc, url, cseHighlightColumns)
+	if err != nil {
+		return nil, err
+	}
+	highlights := make([]*FinancialHighlight, 0, len(t.rows))
+	p := &cellParser{url: url}
+	for row, cells := range t.rows {
+		p.row = row
+		h := &FinancialHighlight{
+			Year:          p.int("year", t.text(cells, "year")),
+			EPS:           p.decimal("eps", t.text(cells, "eps")),
+			NAVPerShare:   p.decimal("nav", t.text(cells, "nav")),
+			NetProfitInMN: p.decimal("net_profit", t.text(cells, "net_profit")),
+		}
+		if p.err != nil {
+			return nil, p.err
+		}
+		highlights = append(highlights, h)
+	}
+	return highlights, nil
+}
@@ -0,0 +1,161 @@
+package bdstockexchange
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/antchfx/htmlquery"
+)
+
+func TestDSE_GetCompany(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		wantErr error
+	}{
+		{"listed", " gp", nil},
+		{"not listed", "NOTLISTED", ErrNoDataFound},
+		{"empty code", "", ErrInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testDSE().GetCompany(context.Background(), tt.code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetCompany() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				assertGolden(t, "dse_company_gp", got)
+			}
+		})
+	}
+}
+
+func TestDSE_GetCompany_escape(t *testing.T) {
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query().Get("name")
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+
+	NewDSE(WithBaseURL(ts.URL)).GetCompany(context.Background(), "A&B #1")
+	if want := "A&B #1"; got != want {
+		t.Errorf("GetCompany() requested the company %q, want %q", got, want)
+	}
+}
+
+func Test_readLabels(t *testing.T) {
+	doc, err := htmlquery.Parse(strings.NewReader(`<table>
+<tr><th>Trading Code: GP</th><th>Scrip Code:</th><th>Sector:</th></tr>
+<tr><td>Last AGM held on:</td><td>21-04-2020</td></tr>
+<tr><th>Market Lot</th><td> 1 </td><th>Market Lot</th><td>2</td></tr>
+</table>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := readLabels(doc)
+	for label, want := range map[string]string{"trading code": "GP", "Scrip Code": "", "SECTOR": "", "Last AGM held on": "21-04-2020", "Market Lot": "1"} {
+		if got, ok := l.get(label); !ok || got != want {
+			t.Errorf("labels.get(%q) = %q, %v, want %q", label, got, ok, want)
+		}
+	}
+	if _, ok := l.get("Face Value"); ok {
+		t.Errorf("labels.get() of a missing label ok = true")
+	}
+}
+
+func TestCSE_GetCompanyDetails(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		golden  string
+		wantErr error
+	}{
+		{"listed", "gp ", "cse_company_details_gp", nil},
+		{"no trade", "EMERALDOIL", "cse_company_details_emeraldoil", nil},
+		{"not listed", "NOTLISTED", "", ErrNoDataFound},
+		{"empty code", " ", "", ErrInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testCSE().GetCompanyDetails(context.Background(), tt.code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetCompanyDetails() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				assertGolden(t, tt.golden, got)
+			}
+		})
+	}
+}
+
+func TestCSE_GetAllCompanyDetails_skip(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		requested []string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.EscapedPath())
+		mu.Unlock()
+		if r.URL.Path == "/company/listedcompanies" {
+			page, err := ioutil.ReadFile(filepath.Join("testdata", "www.cse.com.bd", "company", "listedcompanies.html"))
+			if err != nil {
+				t.Error(err)
+			}
+			// a company without trading code and one with a space in it
+			page = []byte(strings.Replace(string(page), `"https://www.cse.com.bd/company/companydetails/BATBC"`, `""`, 1))
+			page = []byte(strings.Replace(string(page), `companydetails/ACI"`, `companydetails/ACI LTD"`, 1))
+			w.Write(page)
+			return
+		}
+		page, err := ioutil.ReadFile(filepath.Join("testdata", "www.cse.com.bd", "company", "companydetails", path.Base(r.URL.Path)+".html"))
+		if err != nil {
+			// the details page of the other companies lost its labels
+			w.Write([]byte("<html><body></body></html>"))
+			return
+		}
+		w.Write(page)
+	}))
+	defer ts.Close()
+
+	details, err := NewCSE(WithBaseURL(ts.URL)).GetAllCompanyDetails(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("GetAllCompanyDetails() error = %v", err)
+	}
+	var codes []string
+	for _, d := range details {
+		codes = append(codes, d.TradingCode)
+	}
+	if strings.Join(codes, ",") != "EMERALDOIL,GP" {
+		t.Errorf("GetAllCompanyDetails() = %v, want [EMERALDOIL GP]", codes)
+	}
+	if !strings.Contains(strings.Join(requested, " "), "/company/companydetails/ACI%20LTD") {
+		t.Errorf("GetAllCompanyDetails() did not escape the trading code, requested %v", requested)
+	}
+}
+
+func Test_cseCompanyCode(t *testing.T) {
+	tests := []struct {
+		href string
+		want string
+	}{
+		{"https://www.cse.com.bd/company/companydetails/GP", "GP"},
+		{" https://www.cse.com.bd/company/companydetails/1STPRIMFMF/ ", "1STPRIMFMF"},
+		{"/company/companydetails/APEXSPINN", "APEXSPINN"},
+		{"SQURPHARMA", "SQURPHARMA"},
+	}
+	for _, tt := range tests {
+		if got := cseCompanyCode(tt.href); got != tt.want {
+			t.Errorf("cseCompanyCode(%q) = %q, want %q", tt.href, got, tt.want)
+		}
+	}
+}
@@ -37,3 +37,19 @@ const (
 	// SortByPriceChange to sort the result by the Change of Price of the Share
 	SortByPriceChange
 )
+
+// moverKind is the type for the constants of the top mover tables of the dse
+type moverKind uint8
+
+const (
+	// TopGainers are the shares with the largest rise of the price in percent
+	TopGainers moverKind = iota
+	// TopLosers are the shares with the largest fall of the price in percent
+	TopLosers
+	// TopByValue are the shares traded with the largest value
+	TopByValue
+	// TopByVolume are the shares traded with the largest volume
+	TopByVolume
+	// TopByTrade are the shares traded with the largest number of trades
+	TopByTrade
+)
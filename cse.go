@@ -1,38 +1,40 @@
 package bdstockexchange
 
 import (
-	"errors"
+	"context"
 	"fmt"
-	"log"
-	"net/http"
-	"sort"
+	"net/url"
+	"strconv"
 	"strings"
-
-	"golang.org/x/net/html"
-	"golang.org/x/net/html/charset"
+	"time"
 
 	"github.com/antchfx/htmlquery"
 )
 
 // CSE is a struct to access cse related methods
 type CSE struct {
+	client client
 }
 
 // record holds the record data
 type record struct {
 	Title string
-	Value float64
+	Value Decimal
 	Date  string
+	// Time is Date parsed in the Dhaka location
+	Time time.Time
 }
 
 // market holds the market data in a specific date for historical market summary
 type market struct {
-	SL            int
-	Date          string
+	SL   int
+	Date string
+	// Time is Date parsed in the Dhaka location
+	Time          time.Time
 	Trade         int64
 	Volume        int64
-	ValueInTK     float64
-	MarketCapInMN float64
+	ValueInTK     Decimal
+	MarketCapInMN Decimal
 	CSE30         float64
 	CSCX          float64
 	CASPI         float64
@@ -53,7 +55,9 @@ type WeeklyReports struct {
 }
 
 type report struct {
-	Date          string
+	Date string
+	// Time is Date parsed in the Dhaka location
+	Time          time.Time
 	Title         string
 	ReportPDFLink string
 }
@@ -78,11 +82,16 @@ type CompanyListingByCategory struct {
 
 // PriceEarningRatios ...
 type PriceEarningRatios struct {
-	Date                   string
+	// Date is the date of the ratios in the 2006-01-02 format
+	Date string
+	// Time is the date of the ratios in the Dhaka location
+	Time                   time.Time
 	PriceEarningRatioArray []*PriceEarningRatio
 }
 
-// PriceEarningRatio holds the data for a price earning ratio in selected date
+// PriceEarningRatio holds the data for a price earning ratio in selected date.
+// The cse prints the eps the ratios are based on and the dse the ratios of every interim accounts,
+// so the fields only one exchange prints are 0 for the other
 type PriceEarningRatio struct {
 	SL            string
 	TradingCode   string
@@ -91,95 +100,106 @@ type PriceEarningRatio struct {
 		To   string
 	}
 	EPSAsPerUpdatedUnAuditedAccounts struct {
+		Quarter1 Decimal
+		HalfYear Decimal
+		Quarter3 Decimal
+	}
+	AnnualizedEPS                     Decimal
+	EPSBasedOnLastAuditedAccounts     Decimal
+	ClosePrice                        Decimal
+	PERatioBasedOnAnnualizedEPS       float64
+	PERatioBasedOnLastAuditedAccounts float64
+	// PERatioAsPerUpdatedUnAuditedAccounts are the ratios based on the annualized eps of the interim accounts
+	PERatioAsPerUpdatedUnAuditedAccounts struct {
 		Quarter1 float64
 		HalfYear float64
 		Quarter3 float64
 	}
-	AnnualizedEPS                     float64
-	EPSBasedOnLastAuditedAccounts     float64
-	ClosePrice                        float64
-	PERatioBasedOnAnnualizedEPS       float64
-	PERatioBasedOnLastAuditedAccounts float64
 }
 
-const (
-	slCSE = iota
-	stockCodeCSE
-	ltpCSE
-	openCSE
-	highCSE
-	lowCSE
-	ycpCSE
-	tradeCSE
-	valueCSE
-	volumeCSE
-)
+// cseShareColumns are the columns of the cse current price table
+var cseShareColumns = []column{
+	{"sl", []string{"SL", "SL.", "#"}},
+	{"trading_code", []string{"STOCK CODE", "TRADING CODE", "CODE"}},
+	{"ltp", []string{"LTP"}},
+	{"open", []string{"OPEN"}},
+	{"high", []string{"HIGH"}},
+	{"low", []string{"LOW"}},
+	{"ycp", []string{"YCP"}},
+	{"trade", []string{"TRADE"}},
+	{"value", []string{"VALUE (IN MN)", "VALUE (MN)", "VALUE"}},
+	{"volume", []string{"VOLUME"}},
+}
 
 // CSEShare is a model for a single company's latest price data provided by the cse website
 type CSEShare struct {
 	SL          int
 	TradingCode string
-	LTP         float64
-	Open        float64
-	High        float64
-	Low         float64
-	YCP         float64
+	LTP         Decimal
+	Open        Decimal
+	High        Decimal
+	Low         Decimal
+	YCP         Decimal
 	Trade       int64
-	ValueInMN   float64
+	ValueInMN   Decimal
 	Volume      int64
 }
 
-//NewCSE returns new CSE object
-func NewCSE() *CSE {
-	return new(CSE)
+// Change returns the change of the last trade price from yesterday's closing price
+func (s *CSEShare) Change() Decimal {
+	return s.LTP.Sub(s.YCP)
+}
+
+// PercentageChange returns the change of the last trade price from yesterday's closing price in percent.
+// It returns 0 if there is no closing price of yesterday, ex: for a newly listed share
+func (s *CSEShare) PercentageChange() float64 {
+	return percentageChange(s.LTP.Float64(), s.YCP.Float64())
+}
+
+// NewCSE returns new CSE object configured with the given options
+func NewCSE(opts ...Option) *CSE {
+	return &CSE{client: newClient(cseBaseURL, opts)}
+}
+
+// url returns the full url of a cse page
+func (c *CSE) url(path string) string {
+	return c.client.url(cseBaseURL, path)
 }
 
-func getCSELatestPrices() ([]*CSEShare, error) {
+func (c *CSE) getLatestPrices(ctx context.Context) ([]*CSEShare, error) {
 	shares := make([]*CSEShare, 0)
 
-	doc, err := htmlquery.LoadURL("https://www.CSE.com.bd/market/current_price")
+	url := c.url("/market/current_price")
+	doc, err := c.client.loadURL(ctx, url)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	list := htmlquery.Find(doc, `//*[@id="dataTable"]/tbody/tr`)
+	t, err := findTable(doc, url, cseShareColumns)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, v := range list {
-		td := htmlquery.Find(v, "//td")
-		s := &CSEShare{}
-		for i, t := range td {
-			switch i {
-			case slCSE:
-				s.SL = toInt(htmlquery.InnerText(t))
-				break
-			case stockCodeCSE:
-				s.TradingCode = strings.TrimSpace(htmlquery.InnerText(t))
-				break
-			case ltpCSE:
-				s.LTP = toFloat64(htmlquery.InnerText(t))
-				break
-			case openCSE:
-				s.LTP = toFloat64(htmlquery.InnerText(t))
-				break
-			case highCSE:
-				s.High = toFloat64(htmlquery.InnerText(t))
-				break
-			case lowCSE:
-				s.Low = toFloat64(htmlquery.InnerText(t))
-				break
-			case ycpCSE:
-				s.YCP = toFloat64(htmlquery.InnerText(t))
-				break
-			case tradeCSE:
-				s.Trade = toInt64(htmlquery.InnerText(t))
-				break
-			case valueCSE:
-				s.ValueInMN = toFloat64(htmlquery.InnerText(t))
-				break
-			case volumeCSE:
-				s.Volume = toInt64(htmlquery.InnerText(t))
-				break
-			}
+	p := &cellParser{url: url}
+	for row, cells := range t.rows {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		p.row = row
+		s := &CSEShare{
+			SL:          p.int("sl", t.text(cells, "sl")),
+			TradingCode: t.text(cells, "trading_code"),
+			LTP:         p.decimal("ltp", t.text(cells, "ltp")),
+			Open:        p.decimal("open", t.text(cells, "open")),
+			High:        p.decimal("high", t.text(cells, "high")),
+			Low:         p.decimal("low", t.text(cells, "low")),
+			YCP:         p.decimal("ycp", t.text(cells, "ycp")),
+			Trade:       p.int64("trade", t.text(cells, "trade")),
+			ValueInMN:   p.decimal("value", t.text(cells, "value")),
+			Volume:      p.int64("volume", t.text(cells, "volume")),
+		}
+		if p.err != nil {
+			return nil, p.err
 		}
 		shares = append(shares, s)
 	}
@@ -187,124 +207,33 @@ func getCSELatestPrices() ([]*CSEShare, error) {
 }
 
 // GetLatestPrices returns the array of latest share prices or error in case of any error
-// It takes by which field the array should be sorted ex: SortByTradingCode and sort order ex: ASC
-// It will return an error for if user tries to sort with a non existing file in the CSEShare model or invalid category name or invalid sort order
+// It takes by which field the array should be sorted ex: SortByOpeningPrice and sort order ex: ASC
+// It will return an error for if user tries to sort with a non existing file in the CSEShare model or invalid sort order
 func (c *CSE) GetLatestPrices(by sortBy, order sortOrder) ([]*CSEShare, error) {
-	arr, err := getCSELatestPrices()
+	return c.GetLatestPricesContext(context.Background(), by, order)
+}
+
+// GetLatestPricesContext is like GetLatestPrices but takes a context to cancel the request or stop the parsing
+func (c *CSE) GetLatestPricesContext(ctx context.Context, by sortBy, order sortOrder) ([]*CSEShare, error) {
+	arr, err := c.getLatestPrices(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return arr, err
+	return sortCse(arr, by, order)
 }
 
+// sortCse sorts the shares by the field in the order
 func sortCse(arr []*CSEShare, by sortBy, order sortOrder) ([]*CSEShare, error) {
-	if order != ASC && order != DESC {
-		return nil, errors.New("order param is not valid. put a ASC or DESC as order param")
-	}
-	switch by {
-	case SortByTradingCode:
-		if order == ASC {
-			return arr, nil
-		}
-		sort.Slice(arr, func(i, j int) bool {
-			return arr[i].TradingCode > arr[j].TradingCode
-		})
-		return arr, nil
-	case SortByHighPrice:
-		if order == ASC {
-			sort.Slice(arr, func(i, j int) bool {
-				return arr[i].High < arr[j].High
-			})
-			return arr, nil
-		}
-		sort.Slice(arr, func(i, j int) bool {
-			return arr[i].High > arr[j].High
-		})
-		return arr, nil
-	case SortByLTP:
-		if order == ASC {
-			sort.Slice(arr, func(i, j int) bool {
-				return arr[i].LTP < arr[j].LTP
-			})
-			return arr, nil
-		}
-		sort.Slice(arr, func(i, j int) bool {
-			return arr[i].LTP > arr[j].LTP
-		})
-		return arr, nil
-	case SortByOpeningPrice:
-		if order == ASC {
-			sort.Slice(arr, func(i, j int) bool {
-				return arr[i].Open < arr[j].Open
-			})
-			return arr, nil
-		}
-		sort.Slice(arr, func(i, j int) bool {
-			return arr[i].Open > arr[j].Open
-		})
-		return arr, nil
-	case SortByLowPrice:
-		if order == ASC {
-			sort.Slice(arr, func(i, j int) bool {
-				return arr[i].Low < arr[j].Low
-			})
-			return arr, nil
-		}
-		sort.Slice(arr, func(i, j int) bool {
-			return arr[i].Low > arr[j].Low
-		})
-		return arr, nil
-	case SortByNumberOfTrades:
-		if order == ASC {
-			sort.Slice(arr, func(i, j int) bool {
-				return arr[i].Trade < arr[j].Trade
-			})
-			return arr, nil
-		}
-		sort.Slice(arr, func(i, j int) bool {
-			return arr[i].Trade > arr[j].Trade
-		})
-		return arr, nil
-	case SortByValue:
-		if order == ASC {
-			sort.Slice(arr, func(i, j int) bool {
-				return arr[i].ValueInMN < arr[j].ValueInMN
-			})
-			return arr, nil
-		}
-		sort.Slice(arr, func(i, j int) bool {
-			return arr[i].ValueInMN > arr[j].ValueInMN
-		})
-		return arr, nil
-	case SortByVolumeOfShare:
-		if order == ASC {
-			sort.Slice(arr, func(i, j int) bool {
-				return arr[i].Volume < arr[j].Volume
-			})
-			return arr, nil
-		}
-		sort.Slice(arr, func(i, j int) bool {
-			return arr[i].Volume > arr[j].Volume
-		})
-		return arr, nil
-	case SortByYCP:
-		if order == ASC {
-			sort.Slice(arr, func(i, j int) bool {
-				return arr[i].YCP < arr[j].YCP
-			})
-			return arr, nil
-		}
-		sort.Slice(arr, func(i, j int) bool {
-			return arr[i].YCP > arr[j].YCP
-		})
-		return arr, nil
-	default:
-		return nil, errors.New("sorting with the given sort by param is not possible. try another one")
-	}
+	return Query().SortBy(by, order).CSEShares(arr)
 }
 
 // GetMarketSummary returns the summary with highest records till now and the historical market summary data
 func (c *CSE) GetMarketSummary() (*Summary, error) {
+	return c.GetMarketSummaryContext(context.Background())
+}
+
+// GetMarketSummaryContext is like GetMarketSummary but takes a context to cancel the request or stop the parsing
+func (c *CSE) GetMarketSummaryContext(ctx context.Context) (*Summary, error) {
 	summary := &Summary{
 		HighestRecords:      nil,
 		HistoricalSummaries: nil,
@@ -312,11 +241,14 @@ func (c *CSE) GetMarketSummary() (*Summary, error) {
 	highestRecords := make([]*record, 0)
 	historicalSummaries := make([]*market, 0)
 
-	doc, err := htmlquery.LoadURL("https://www.cse.com.bd/market/historical_market")
+	url := c.url("/market/historical_market")
+	doc, err := c.client.loadURL(ctx, url)
 	if err != nil {
-		return nil, errErrorFetchingUrl
+		return nil, err
 	}
 
+	p := &cellParser{url: url}
+
 	// process to get the highest records data
 	list := htmlquery.Find(doc, `//*[@id="wrapper"]/div/div/div[1]/div/div[3]/div[1]/div/div/div`)
 
@@ -326,13 +258,19 @@ func (c *CSE) GetMarketSummary() (*Summary, error) {
 			if i == 0 {
 				continue
 			}
-			recordTitle := htmlquery.FindOne(v, `//*[@id="highscore_tab_1"]`)
-			recordValue := htmlquery.FindOne(v, `//*[@id="highscore_tab_2"]`)
-			recordDate := htmlquery.FindOne(v, `//*[@id="highscore_tab_3"]`)
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			p.row = i
+			recordDate := p.find(v, "date", `//*[@id="highscore_tab_3"]`)
 			r := &record{
-				Title: htmlquery.InnerText(recordTitle),
-				Value: toFloat64(htmlquery.InnerText(recordValue)),
-				Date:  htmlquery.InnerText(recordDate),
+				Title: p.find(v, "title", `//*[@id="highscore_tab_1"]`),
+				Value: p.decimal("value", p.find(v, "value", `//*[@id="highscore_tab_2"]`)),
+				Date:  recordDate,
+				Time:  p.date("date", recordDate, dateLayouts...),
+			}
+			if p.err != nil {
+				return nil, p.err
 			}
 			highestRecords = append(highestRecords, r)
 		}
@@ -347,31 +285,28 @@ func (c *CSE) GetMarketSummary() (*Summary, error) {
 			if i == 0 {
 				continue
 			}
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			p.row = i
 
-			historySL := htmlquery.FindOne(v, `//*[@id="market_tab_1"]`)
-			historyDate := htmlquery.FindOne(v, `//*[@id="market_tab_2"]`)
-			historyTrade := htmlquery.FindOne(v, `//*[@id="market_tab_3"]`)
-			historyVolume := htmlquery.FindOne(v, `//*[@id="market_tab_4"]`)
-			historyValueInTK := htmlquery.FindOne(v, `//*[@id="market_tab_5"]`)
-			historyMarketCapInMN := htmlquery.FindOne(v, `//*[@id="market_tab_6"]`)
-			historyCSE30 := htmlquery.FindOne(v, `//*[@id="market_tab_7"]`)
-			historyCSCX := htmlquery.FindOne(v, `//*[@id="market_tab_8"]`)
-			historyCASPI := htmlquery.FindOne(v, `//*[@id="market_tab_9"]`)
-			historyCSE50 := htmlquery.FindOne(v, `//*[@id="market_tab_10"]`)
-			historyCSI := htmlquery.FindOne(v, `//*[@id="market_tab_11"]`)
-
+			historyDate := p.find(v, "date", `//*[@id="market_tab_2"]`)
 			m := &market{
-				SL:            toInt(htmlquery.InnerText(historySL)),
-				Date:          htmlquery.InnerText(historyDate),
-				Trade:         toInt64(htmlquery.InnerText(historyTrade)),
-				Volume:        toInt64(htmlquery.InnerText(historyVolume)),
-				ValueInTK:     toFloat64(htmlquery.InnerText(historyValueInTK)),
-				MarketCapInMN: toFloat64(htmlquery.InnerText(historyMarketCapInMN)),
-				CSE30:         toFloat64(htmlquery.InnerText(historyCSE30)),
-				CSCX:          toFloat64(htmlquery.InnerText(historyCSCX)),
-				CASPI:         toFloat64(htmlquery.InnerText(historyCASPI)),
-				CSE50:         toFloat64(htmlquery.InnerText(historyCSE50)),
-				CSI:           toFloat64(htmlquery.InnerText(historyCSI)),
+				SL:            p.int("sl", p.find(v, "sl", `//*[@id="market_tab_1"]`)),
+				Date:          historyDate,
+				Time:          p.date("date", historyDate, dateLayouts...),
+				Trade:         p.int64("trade", p.find(v, "trade", `//*[@id="market_tab_3"]`)),
+				Volume:        p.int64("volume", p.find(v, "volume", `//*[@id="market_tab_4"]`)),
+				ValueInTK:     p.decimal("value_in_tk", p.find(v, "value_in_tk", `//*[@id="market_tab_5"]`)),
+				MarketCapInMN: p.decimal("market_cap_in_mn", p.find(v, "market_cap_in_mn", `//*[@id="market_tab_6"]`)),
+				CSE30:         p.float64("cse30", p.find(v, "cse30", `//*[@id="market_tab_7"]`)),
+				CSCX:          p.float64("cscx", p.find(v, "cscx", `//*[@id="market_tab_8"]`)),
+				CASPI:         p.float64("caspi", p.find(v, "caspi", `//*[@id="market_tab_9"]`)),
+				CSE50:         p.float64("cse50", p.find(v, "cse50", `//*[@id="market_tab_10"]`)),
+				CSI:           p.float64("csi", p.find(v, "csi", `//*[@id="market_tab_11"]`)),
+			}
+			if p.err != nil {
+				return nil, p.err
 			}
 			historicalSummaries = append(historicalSummaries, m)
 		}
@@ -382,45 +317,19 @@ func (c *CSE) GetMarketSummary() (*Summary, error) {
 	return summary, nil
 }
 
-// GetAllWeeklyReports returns weekly reports pdf link for the input Year. the Year should be between current Year and 2018
+// GetAllWeeklyReports returns weekly reports pdf link for the input Year. the Year should be between current Year and 2018.
+// The search form is fetched before the first post of the client for the session cookies and its hidden fields,
+// like the csrf token, which are reused by the later posts
 func (c *CSE) GetAllWeeklyReports(year int) (*WeeklyReports, error) {
-	data := fmt.Sprintf("Year=%d", year)
-	body := strings.NewReader(data)
-	req, err := http.NewRequest("POST", "https://www.cse.com.bd/market/weekly_report", body)
-	if err != nil {
-		// handle err
-	}
-	req.Header.Set("Authority", "www.cse.com.bd")
-	req.Header.Set("Cache-Control", "max-age=0")
-	req.Header.Set("Upgrade-Insecure-Requests", "1")
-	req.Header.Set("Origin", "https://www.cse.com.bd")
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Linux; Android 6.0.1; Moto G (4)) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/84.0.4147.89 Mobile Safari/537.36")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.9")
-	req.Header.Set("Sec-Fetch-Site", "same-origin")
-	req.Header.Set("Sec-Fetch-Mode", "navigate")
-	req.Header.Set("Sec-Fetch-User", "?1")
-	req.Header.Set("Sec-Fetch-Dest", "document")
-	req.Header.Set("Referer", "https://www.cse.com.bd/market/weekly_report")
-	req.Header.Set("Accept-Language", "en-GB,en-US;q=0.9,en;q=0.8")
-	req.Header.Set("Cookie", "logins=281d8de2fe59ebd74a2fb76b0f75bcbf229bd7f1")
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		// handle err
-	}
-	//defer resp.Body.Close()
-
-	r, err := charset.NewReader(resp.Body, resp.Header.Get("Content-Type"))
-
-	if err != nil {
-		// handle err
-	}
-
-	doc, err := html.Parse(r)
+	return c.GetAllWeeklyReportsContext(context.Background(), year)
+}
 
+// GetAllWeeklyReportsContext is like GetAllWeeklyReports but takes a context to cancel the request or stop the parsing
+func (c *CSE) GetAllWeeklyReportsContext(ctx context.Context, year int) (*WeeklyReports, error) {
+	page := c.url("/market/weekly_report")
+	doc, err := c.client.postForm(ctx, page, url.Values{"Year": {strconv.Itoa(year)}})
 	if err != nil {
-		// handle error
+		return nil, err
 	}
 
 	reports := make([]*report, 0)
@@ -429,18 +338,23 @@ func (c *CSE) GetAllWeeklyReports(year int) (*WeeklyReports, error) {
 		Reports: nil,
 	}
 
+	p := &cellParser{url: page}
 	availableYears := make([]int, 0)
 	list := htmlquery.Find(doc, `//*[@id="wrapper"]/div/div/div[1]/div/div[1]/div/div/form/div/div[2]/select`)
 	for _, v := range list {
 		option := htmlquery.Find(v, "option")
-		for _, v := range option {
+		for i, v := range option {
 			if htmlquery.InnerText(v) == "" {
 				continue
 			} else {
-				availableYears = append(availableYears, toInt(htmlquery.InnerText(v)))
+				p.row = i
+				availableYears = append(availableYears, p.int("year", htmlquery.InnerText(v)))
 			}
 		}
 	}
+	if p.err != nil {
+		return nil, p.err
+	}
 
 	isValidYear := false
 
@@ -452,7 +366,7 @@ func (c *CSE) GetAllWeeklyReports(year int) (*WeeklyReports, error) {
 	}
 
 	if isValidYear == false {
-		return nil, errNotAValidYear
+		return nil, ErrNotAValidYear
 	}
 
 	list = htmlquery.Find(doc, `//*[@id="wrapper"]/div/div/div[1]/div/div[3]/div/div/div[2]/div`)
@@ -461,15 +375,16 @@ func (c *CSE) GetAllWeeklyReports(year int) (*WeeklyReports, error) {
 		peRatioTabsContent := htmlquery.Find(v, "div")
 		for _, v := range peRatioTabsContent {
 			if htmlquery.SelectAttr(v, "class") == "pe_ratio_tabs_cont" {
-				date := htmlquery.InnerText(htmlquery.FindOne(v, `//*[@id="pe_ratiocont_1"]`))
-				titleNode := htmlquery.FindOne(v, `//*[@id="pe_ratiocont_2"]`)
-				title := htmlquery.InnerText(titleNode)
-				link := htmlquery.InnerText(htmlquery.FindOne(titleNode, "//a/@href"))
-
+				p.row = len(reports)
+				date := p.find(v, "date", `//*[@id="pe_ratiocont_1"]`)
 				rep := &report{
 					Date:          date,
-					Title:         title,
-					ReportPDFLink: link,
+					Time:          p.date("date", date, dateLayouts...),
+					Title:         p.find(v, "title", `//*[@id="pe_ratiocont_2"]`),
+					ReportPDFLink: p.find(v, "link", `//*[@id="pe_ratiocont_2"]//a/@href`),
+				}
+				if p.err != nil {
+					return nil, p.err
 				}
 				reports = append(reports, rep)
 			}
@@ -483,12 +398,19 @@ func (c *CSE) GetAllWeeklyReports(year int) (*WeeklyReports, error) {
 
 // GetAllListedCompanies returns all the companies listed in cse or error in case of any error
 func (c *CSE) GetAllListedCompanies() ([]*Company, error) {
-	doc, err := htmlquery.LoadURL("https://www.cse.com.bd/company/listedcompanies")
+	return c.GetAllListedCompaniesContext(context.Background())
+}
+
+// GetAllListedCompaniesContext is like GetAllListedCompanies but takes a context to cancel the request or stop the parsing
+func (c *CSE) GetAllListedCompaniesContext(ctx context.Context) ([]*Company, error) {
+	url := c.url("/company/listedcompanies")
+	doc, err := c.client.loadURL(ctx, url)
 	if err != nil {
 		return nil, err
 	}
 
 	companyListing := make([]*Company, 0)
+	p := &cellParser{url: url}
 
 	list, err := htmlquery.QueryAll(doc, `//*[@id="top_content_1"]/div/div/div/div/div/div/div[2]`)
 	if err != nil {
@@ -499,13 +421,15 @@ func (c *CSE) GetAllListedCompanies() ([]*Company, error) {
 		for _, di := range div {
 			li := htmlquery.Find(di, "li")
 			for _, v := range li {
-				a := htmlquery.FindOne(v, "a")
-				companyName := strings.TrimSpace(htmlquery.InnerText(a))
-				companyTradingCode := strings.Trim(htmlquery.InnerText(htmlquery.FindOne(v, "//a/@href")), "https://www.cse.com.bd/company/companydetails/")
-
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
 				company := &Company{
-					CompanyName: companyName,
-					TradingCode: companyTradingCode,
+					CompanyName: strings.TrimSpace(p.find(v, "company_name", "a")),
+					TradingCode: cseCompanyCode(p.find(v, "trading_code", "//a/@href")),
+				}
+				if p.err != nil {
+					return nil, p.err
 				}
 				companyListing = append(companyListing, company)
 			}
@@ -518,12 +442,19 @@ func (c *CSE) GetAllListedCompanies() ([]*Company, error) {
 
 // GetAllListedCompaniesByIndustry returns list of companies with their industry type or error in case of any error
 func (c *CSE) GetAllListedCompaniesByIndustry() ([]*CompanyListingByIndustry, error) {
-	doc, err := htmlquery.LoadURL("https://www.cse.com.bd/company/listedcompanies")
+	return c.GetAllListedCompaniesByIndustryContext(context.Background())
+}
+
+// GetAllListedCompaniesByIndustryContext is like GetAllListedCompaniesByIndustry but takes a context to cancel the request or stop the parsing
+func (c *CSE) GetAllListedCompaniesByIndustryContext(ctx context.Context) ([]*CompanyListingByIndustry, error) {
+	url := c.url("/company/listedcompanies")
+	doc, err := c.client.loadURL(ctx, url)
 	if err != nil {
 		return nil, err
 	}
 
 	companyListIndustry := make([]*CompanyListingByIndustry, 0)
+	p := &cellParser{url: url}
 
 	list, err := htmlquery.QueryAll(doc, `//*[@id="top_content_2"]/div/div/div/div/div`)
 	if err != nil {
@@ -532,28 +463,33 @@ func (c *CSE) GetAllListedCompaniesByIndustry() ([]*CompanyListingByIndustry, er
 
 	for _, li := range list {
 		divs, err := htmlquery.QueryAll(li, "div")
-		//log.Println(htmlquery.InnerText(htmlquery.FindOne(divs, "//@id")))
 		if err != nil {
 			return nil, err
 		}
 		for _, div := range divs {
 			list := make([]*Company, 0)
-			category := htmlquery.FindOne(div, "//@id")
+			p.row = len(companyListIndustry)
+			industry := p.find(div, "industry", "//@id")
+			if p.err != nil {
+				return nil, p.err
+			}
 
 			li := htmlquery.Find(div, "//ul//li")
 			for _, v := range li {
-				a := htmlquery.FindOne(v, "a")
-				companyName := strings.TrimSpace(htmlquery.InnerText(a))
-				companyTradingCode := strings.Trim(htmlquery.InnerText(htmlquery.FindOne(v, "//a/@href")), "https://www.cse.com.bd/company/companydetails/")
-
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
 				company := &Company{
-					CompanyName: companyName,
-					TradingCode: companyTradingCode,
+					CompanyName: strings.TrimSpace(p.find(v, "company_name", "a")),
+					TradingCode: cseCompanyCode(p.find(v, "trading_code", "//a/@href")),
+				}
+				if p.err != nil {
+					return nil, p.err
 				}
 				list = append(list, company)
 			}
 			listByIndustry := &CompanyListingByIndustry{
-				IndustryType: htmlquery.InnerText(category),
+				IndustryType: industry,
 				List:         list,
 			}
 			companyListIndustry = append(companyListIndustry, listByIndustry)
@@ -564,12 +500,19 @@ func (c *CSE) GetAllListedCompaniesByIndustry() ([]*CompanyListingByIndustry, er
 
 // GetAllListedCompaniesByCategory returns the listing of the companies by their category or an error in case of any error
 func (c *CSE) GetAllListedCompaniesByCategory() ([]*CompanyListingByCategory, error) {
-	doc, err := htmlquery.LoadURL("https://www.cse.com.bd/company/listedcompanies")
+	return c.GetAllListedCompaniesByCategoryContext(context.Background())
+}
+
+// GetAllListedCompaniesByCategoryContext is like GetAllListedCompaniesByCategory but takes a context to cancel the request or stop the parsing
+func (c *CSE) GetAllListedCompaniesByCategoryContext(ctx context.Context) ([]*CompanyListingByCategory, error) {
+	url := c.url("/company/listedcompanies")
+	doc, err := c.client.loadURL(ctx, url)
 	if err != nil {
 		return nil, err
 	}
 
 	companyListByCategory := make([]*CompanyListingByCategory, 0)
+	p := &cellParser{url: url}
 
 	list, err := htmlquery.QueryAll(doc, `//*[@id="top_content_3"]/div/div/div/div/div`)
 
@@ -579,29 +522,34 @@ func (c *CSE) GetAllListedCompaniesByCategory() ([]*CompanyListingByCategory, er
 
 	for _, li := range list {
 		divs, err := htmlquery.QueryAll(li, "div")
-		//log.Println(htmlquery.InnerText(htmlquery.FindOne(divs, "//@id")))
 		if err != nil {
 			return nil, err
 		}
 
 		for _, div := range divs {
 			list := make([]*Company, 0)
-			category := htmlquery.FindOne(div, "div")
+			p.row = len(companyListByCategory)
+			category := p.find(div, "category", "div")
+			if p.err != nil {
+				return nil, p.err
+			}
 
 			li := htmlquery.Find(div, "//ul//li")
 			for _, v := range li {
-				a := htmlquery.FindOne(v, "a")
-				companyName := strings.TrimSpace(htmlquery.InnerText(a))
-				companyTradingCode := strings.Trim(htmlquery.InnerText(htmlquery.FindOne(v, "//a/@href")), "https://www.cse.com.bd/company/companydetails/")
-
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
 				company := &Company{
-					CompanyName: companyName,
-					TradingCode: companyTradingCode,
+					CompanyName: strings.TrimSpace(p.find(v, "company_name", "a")),
+					TradingCode: cseCompanyCode(p.find(v, "trading_code", "//a/@href")),
+				}
+				if p.err != nil {
+					return nil, p.err
 				}
 				list = append(list, company)
 			}
 			listByCategory := &CompanyListingByCategory{
-				Category: strings.TrimSpace(htmlquery.InnerText(category)),
+				Category: strings.TrimSpace(category),
 				List:     list,
 			}
 			companyListByCategory = append(companyListByCategory, listByCategory)
@@ -611,54 +559,28 @@ func (c *CSE) GetAllListedCompaniesByCategory() ([]*CompanyListingByCategory, er
 }
 
 // GetPriceEarningRatio returns the price earning ratio data for listed companies as per input date. It takes day, month and Year as input ex : (03, 07, 2020)
-// where 03 is the day and 07 is the month and 2020 is the Year. Don't forget to include 0 before single digit day or month
+// where 03 is the day and 07 is the month and 2020 is the Year. The 0 before a single digit day or month may be left out.
+// It returns an error wrapping ErrInvalidArgument for a date which does not exist and ErrNoDataFound if the market was closed on the date.
+// The search form is posted like the one of GetAllWeeklyReports
 func (c *CSE) GetPriceEarningRatio(day, month, year string) (*PriceEarningRatios, error) {
-	priceEarningRatios := &PriceEarningRatios{
-		Date:                   "",
-		PriceEarningRatioArray: nil,
-	}
-
-	priceEarningRatioArray := make([]*PriceEarningRatio, 0)
-
-	data := fmt.Sprintf("pe_date=%s-%s-%s", year, month, day)
-	dataBody := strings.NewReader(data)
-	req, err := http.NewRequest("POST", "https://www.cse.com.bd/market/pe_ratio", dataBody)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Authority", "www.cse.com.bd")
-	req.Header.Set("Cache-Control", "max-age=0")
-	req.Header.Set("Upgrade-Insecure-Requests", "1")
-	req.Header.Set("Origin", "https://www.cse.com.bd")
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Linux; Android 6.0; Nexus 5 Build/MRA58N) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/84.0.4147.89 Mobile Safari/537.36")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.9")
-	req.Header.Set("Sec-Fetch-Site", "same-origin")
-	req.Header.Set("Sec-Fetch-Mode", "navigate")
-	req.Header.Set("Sec-Fetch-User", "?1")
-	req.Header.Set("Sec-Fetch-Dest", "document")
-	req.Header.Set("Referer", "https://www.cse.com.bd/market/pe_ratio")
-	req.Header.Set("Accept-Language", "en-GB,en-US;q=0.9,en;q=0.8")
-	req.Header.Set("Cookie", "logins=e879d1d3a477b3e43ddbb30e4d46ad4feddddfea")
-
-	resp, err := http.DefaultClient.Do(req)
+	return c.GetPriceEarningRatioContext(context.Background(), day, month, year)
+}
 
-	if err != nil {
-		return nil, err
+// GetPriceEarningRatioContext is like GetPriceEarningRatio but takes a context to cancel the request or stop the parsing
+func (c *CSE) GetPriceEarningRatioContext(ctx context.Context, day, month, year string) (*PriceEarningRatios, error) {
+	peDate, err := toTime(fmt.Sprintf("%s-%s-%s", strings.TrimSpace(year), strings.TrimSpace(month), strings.TrimSpace(day)), "2006-1-2")
+	if err != nil || peDate.IsZero() {
+		return nil, fmt.Errorf("%w: date %s-%s-%s is not valid", ErrInvalidArgument, year, month, day)
 	}
+	return c.getPriceEarningRatio(ctx, peDate)
+}
 
-	defer resp.Body.Close()
-
-	//re, _ := ioutil.ReadAll(resp.Body)
-	//log.Println(string(re))
-
-	r, err := charset.NewReader(resp.Body, resp.Header.Get("Content-Type"))
-	if err != nil {
-		return nil, err
-	}
+// getPriceEarningRatio returns the price earning ratios of the cse on the day of the date
+func (c *CSE) getPriceEarningRatio(ctx context.Context, peDate time.Time) (*PriceEarningRatios, error) {
+	priceEarningRatioArray := make([]*PriceEarningRatio, 0)
 
-	doc, err := html.Parse(r)
+	page := c.url("/market/pe_ratio")
+	doc, err := c.client.postForm(ctx, page, url.Values{"pe_date": {peDate.Format("2006-01-02")}})
 	if err != nil {
 		return nil, err
 	}
@@ -669,6 +591,7 @@ func (c *CSE) GetPriceEarningRatio(day, month, year string) (*PriceEarningRatios
 	}
 
 	var isDataFound bool
+	p := &cellParser{url: page}
 
 	for _, v := range list {
 		tabsContents, err := htmlquery.QueryAll(v, "//div")
@@ -677,48 +600,41 @@ func (c *CSE) GetPriceEarningRatio(day, month, year string) (*PriceEarningRatios
 		}
 		isDataFound = false
 		for _, v := range tabsContents {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
 			if htmlquery.SelectAttr(v, "class") == "pe_ratio_tabs_cont" {
 				isDataFound = true
-				peRatiocont1 := htmlquery.FindOne(v, `//*[@id="pe_ratiocont_1"]`)
-				peRatiocont2 := htmlquery.FindOne(v, `//*[@id="pe_ratiocont_2"]`)
-				peRatiocont3Td1 := htmlquery.FindOne(v, `//*[@id="pe_ratiocont_3"]/table/tbody/tr/td[1]`)
-				peRatiocont3Td2 := htmlquery.FindOne(v, `//*[@id="pe_ratiocont_3"]/table/tbody/tr/td[2]`)
-				//log.Println(htmlquery.InnerText(pe_ratiocont_3_td1), htmlquery.InnerText(pe_ratiocont_3_td2))
-				peRatiocont4Rd1 := htmlquery.FindOne(v, `//*[@id="pe_ratiocont_4"]/table/tbody/tr/td[1]`)
-				peRatiocont4Td2 := htmlquery.FindOne(v, `//*[@id="pe_ratiocont_4"]/table/tbody/tr/td[2]`)
-				peRatiocont4Td3 := htmlquery.FindOne(v, `//*[@id="pe_ratiocont_4"]/table/tbody/tr/td[3]`)
-				peRatiocont5 := htmlquery.FindOne(v, `//*[@id="pe_ratiocont_5"]`)
-				peRatiocont6 := htmlquery.FindOne(v, `//*[@id="pe_ratiocont_6"]`)
-				peRatiocont7 := htmlquery.FindOne(v, `//*[@id="pe_ratiocont_7"]`)
-				peRatiocont8 := htmlquery.FindOne(v, `//*[@id="pe_ratiocont_8"]`)
-				peRatiocont9 := htmlquery.FindOne(v, `//*[@id="pe_ratiocont_9"]`)
-
+				p.row = len(priceEarningRatioArray)
 				priceEarningRatio := &PriceEarningRatio{
-					SL:          strings.Replace(htmlquery.InnerText(peRatiocont1), ".", "", -1),
-					TradingCode: htmlquery.InnerText(peRatiocont2),
+					SL:          strings.Replace(p.find(v, "sl", `//*[@id="pe_ratiocont_1"]`), ".", "", -1),
+					TradingCode: p.find(v, "trading_code", `//*[@id="pe_ratiocont_2"]`),
 					FinancialYear: struct {
 						From string
 						To   string
 					}{
-						From: htmlquery.InnerText(peRatiocont3Td1),
-						To:   htmlquery.InnerText(peRatiocont3Td2),
+						From: p.find(v, "financial_year_from", `//*[@id="pe_ratiocont_3"]/table/tbody/tr/td[1]`),
+						To:   p.find(v, "financial_year_to", `//*[@id="pe_ratiocont_3"]/table/tbody/tr/td[2]`),
 					},
 					EPSAsPerUpdatedUnAuditedAccounts: struct {
-						Quarter1 float64
-						HalfYear float64
-						Quarter3 float64
+						Quarter1 Decimal
+						HalfYear Decimal
+						Quarter3 Decimal
 					}{
-						Quarter1: toFloat64(htmlquery.InnerText(peRatiocont4Rd1)),
-						HalfYear: toFloat64(htmlquery.InnerText(peRatiocont4Td2)),
-						Quarter3: toFloat64(htmlquery.InnerText(peRatiocont4Td3)),
+						Quarter1: p.decimal("eps_quarter1", p.find(v, "eps_quarter1", `//*[@id="pe_ratiocont_4"]/table/tbody/tr/td[1]`)),
+						HalfYear: p.decimal("eps_half_year", p.find(v, "eps_half_year", `//*[@id="pe_ratiocont_4"]/table/tbody/tr/td[2]`)),
+						Quarter3: p.decimal("eps_quarter3", p.find(v, "eps_quarter3", `//*[@id="pe_ratiocont_4"]/table/tbody/tr/td[3]`)),
 					},
-					AnnualizedEPS:                     toFloat64(htmlquery.InnerText(peRatiocont5)),
-					EPSBasedOnLastAuditedAccounts:     toFloat64(htmlquery.InnerText(peRatiocont6)),
-					ClosePrice:                        toFloat64(htmlquery.InnerText(peRatiocont7)),
-					PERatioBasedOnAnnualizedEPS:       toFloat64(htmlquery.InnerText(peRatiocont8)),
-					PERatioBasedOnLastAuditedAccounts: toFloat64(htmlquery.InnerText(peRatiocont9)),
+					AnnualizedEPS:                     p.decimal("annualized_eps", p.find(v, "annualized_eps", `//*[@id="pe_ratiocont_5"]`)),
+					EPSBasedOnLastAuditedAccounts:     p.decimal("eps_based_on_last_audited_accounts", p.find(v, "eps_based_on_last_audited_accounts", `//*[@id="pe_ratiocont_6"]`)),
+					ClosePrice:                        p.decimal("close_price", p.find(v, "close_price", `//*[@id="pe_ratiocont_7"]`)),
+					PERatioBasedOnAnnualizedEPS:       p.float64("pe_ratio_based_on_annualized_eps", p.find(v, "pe_ratio_based_on_annualized_eps", `//*[@id="pe_ratiocont_8"]`)),
+					PERatioBasedOnLastAuditedAccounts: p.float64("pe_ratio_based_on_last_audited_accounts", p.find(v, "pe_ratio_based_on_last_audited_accounts", `//*[@id="pe_ratiocont_9"]`)),
 				}
 
+				if p.err != nil {
+					return nil, p.err
+				}
 				priceEarningRatioArray = append(priceEarningRatioArray, priceEarningRatio)
 
 			}
@@ -726,13 +642,14 @@ func (c *CSE) GetPriceEarningRatio(day, month, year string) (*PriceEarningRatios
 
 	}
 	if !isDataFound {
-		return nil, errNoDataFound
+		return nil, fmt.Errorf("%w: no price earning ratio on %s", ErrNoDataFound, peDate.Format("2006-01-02"))
 	}
 
-	priceEarningRatios.Date = data
-	priceEarningRatios.PriceEarningRatioArray = priceEarningRatioArray
-
-	return priceEarningRatios, nil
+	return &PriceEarningRatios{
+		Date:                   peDate.Format("2006-01-02"),
+		Time:                   peDate,
+		PriceEarningRatioArray: priceEarningRatioArray,
+	}, nil
 }
 
 // CseMarketStatus holds the data for if market is open/close
@@ -742,7 +659,12 @@ type CseMarketStatus struct {
 
 // GetMarketStatus returns the CseMarketStatus with is open/close
 func (c *CSE) GetMarketStatus() (*CseMarketStatus, error) {
-	doc, err := htmlquery.LoadURL("https://www.cse.com.bd/market/current_price")
+	return c.GetMarketStatusContext(context.Background())
+}
+
+// GetMarketStatusContext is like GetMarketStatus but takes a context to cancel the request or stop the parsing
+func (c *CSE) GetMarketStatusContext(ctx context.Context) (*CseMarketStatus, error) {
+	doc, err := c.client.loadURL(ctx, c.url("/market/current_price"))
 	if err != nil {
 		return nil, err
 	}
@@ -751,6 +673,9 @@ func (c *CSE) GetMarketStatus() (*CseMarketStatus, error) {
 		return nil, err
 	}
 
+	if isOpenNode == nil {
+		return nil, errLayoutChanged(c.url("/market/current_price"), "market status")
+	}
 	isOpenText := htmlquery.InnerText(isOpenNode)
 
 	isOpen := false
@@ -763,7 +688,102 @@ func (c *CSE) GetMarketStatus() (*CseMarketStatus, error) {
 		IsOpen: isOpen,
 	}
 
-	log.Println(cseMarketStatus.IsOpen)
-
 	return cseMarketStatus, nil
 }
+
+// cseIndexColumns are the columns of the index table of the cse home page
+var cseIndexColumns = []column{
+	{"index", []string{"INDEX", "INDEX NAME"}},
+	{"value", []string{"VALUE", "CURRENT VALUE"}},
+	{"change", []string{"CHANGE"}},
+	{"percentage_change", []string{"% CHANGE", "%CHANGE", "CHANGE (%)"}},
+}
+
+// cseTotalColumns are the columns of the trade table of the cse home page
+var cseTotalColumns = []column{
+	{"trade", []string{"TRADE", "TOTAL TRADE"}},
+	{"volume", []string{"VOLUME", "TOTAL VOLUME"}},
+	{"value", []string{"VALUE (In Mn)", "VALUE (MN)", "TOTAL VALUE (In Mn)"}},
+	{"advanced", []string{"ADVANCED", "ISSUES ADVANCED"}},
+	{"declined", []string{"DECLINED", "ISSUES DECLINED"}},
+	{"unchanged", []string{"UNCHANGED", "ISSUES UNCHANGED"}},
+}
+
+// IndexSnapshot holds the current value of every cse index and the totals of the day printed on the cse home page
+type IndexSnapshot struct {
+	// Date is the day printed on the page header and Time is Date parsed in the Dhaka location
+	Date string
+	Time time.Time
+	// Indices are the CASPI, CSE30, CSCX, CSE50 and CSI indices in the order the page prints them
+	Indices         []*Index
+	TotalTrade      int64
+	TotalVolume     int64
+	TotalValueInMN  Decimal
+	IssuesAdvanced  int32
+	IssuesDeclined  int32
+	IssuesUnchanged int32
+}
+
+// Index returns the index with the name, ex: "CASPI", or nil if the snapshot does not have it
+func (s *IndexSnapshot) Index(name string) *Index {
+	for _, i := range s.Indices {
+		if strings.EqualFold(i.Name, name) {
+			return i
+		}
+	}
+	return nil
+}
+
+// GetIndexSnapshot returns the current value, change and percentage change of every cse index with the total trade,
+// volume and value and the advanced, declined and unchanged issues of the day from the cse home page
+func (c *CSE) GetIndexSnapshot(ctx context.Context) (*IndexSnapshot, error) {
+	url := c.url("/")
+	doc, err := c.client.loadURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &IndexSnapshot{}
+	p := &cellParser{url: url}
+	if day := htmlquery.FindOne(doc, `//div[@class="status_time"]`); day != nil {
+		snapshot.Date = strings.TrimSpace(htmlquery.InnerText(day))
+		snapshot.Time = p.date("date", snapshot.Date, dateLayouts...)
+	}
+
+	indices, err := findTable(doc, url, cseIndexColumns)
+	if err != nil {
+		return nil, err
+	}
+	for row, cells := range indices.rows {
+		p.row = row
+		snapshot.Indices = append(snapshot.Indices, &Index{
+			Name:             indices.text(cells, "index"),
+			Value:            p.float64("value", indices.text(cells, "value")),
+			Change:           p.float64("change", indices.text(cells, "change")),
+			PercentageChange: p.float64("percentage_change", strings.TrimSuffix(indices.text(cells, "percentage_change"), "%")),
+		})
+	}
+	if len(snapshot.Indices) == 0 {
+		return nil, errLayoutChanged(url, "index rows")
+	}
+
+	totals, err := findTable(doc, url, cseTotalColumns)
+	if err != nil {
+		return nil, err
+	}
+	if len(totals.rows) == 0 {
+		return nil, errLayoutChanged(url, "trade row")
+	}
+	cells := totals.rows[0]
+	p.row = 0
+	snapshot.TotalTrade = p.int64("trade", totals.text(cells, "trade"))
+	snapshot.TotalVolume = p.int64("volume", totals.text(cells, "volume"))
+	snapshot.TotalValueInMN = p.decimal("value", totals.text(cells, "value"))
+	snapshot.IssuesAdvanced = int32(p.int("advanced", totals.text(cells, "advanced")))
+	snapshot.IssuesDeclined = int32(p.int("declined", totals.text(cells, "declined")))
+	snapshot.IssuesUnchanged = int32(p.int("unchanged", totals.text(cells, "unchanged")))
+	if p.err != nil {
+		return nil, p.err
+	}
+	return snapshot, nil
+}
@@ -1,7 +1,14 @@
 package bdstockexchange
 
 import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -11,7 +18,7 @@ func TestNewCSE(t *testing.T) {
 		want *CSE
 	}{
 		// TODO: Add test cases.
-		{"new", new(CSE)},
+		{"new", &CSE{client: client{baseURL: cseBaseURL, session: newSession()}}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -29,11 +36,11 @@ func Test_getCSELatestPrices(t *testing.T) {
 		wantErr bool
 	}{
 		// TODO: Add test cases.
-		{"", make([]*CSEShare, 329), false},
+		{"", make([]*CSEShare, 6), false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := getCSELatestPrices()
+			got, err := testCSE().getLatestPrices(context.Background())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("getCSELatestPrices() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -58,11 +65,11 @@ func TestCSE_GetLatestPrices(t *testing.T) {
 		wantErr bool
 	}{
 		// TODO: Add test cases.
-		{"", NewCSE(), args{SortByHighPrice, ASC}, make([]*CSEShare, 329), false},
+		{"", testCSE(), args{SortByHighPrice, ASC}, make([]*CSEShare, 6), false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := &CSE{}
+			c := tt.c
 			got, err := c.GetLatestPrices(tt.args.by, tt.args.order)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CSE.GetLatestPrices() error = %v, wantErr %v", err, tt.wantErr)
@@ -75,6 +82,53 @@ func TestCSE_GetLatestPrices(t *testing.T) {
 	}
 }
 
+func TestCSE_GetLatestPrices_sorted(t *testing.T) {
+	tests := []struct {
+		by    sortBy
+		order sortOrder
+		first string
+		last  string
+	}{
+		{SortByTradingCode, ASC, "ACI", "SQURPHARMA"},
+		{SortByTradingCode, DESC, "SQURPHARMA", "ACI"},
+		{SortByOpeningPrice, ASC, "BEXIMCO", "BATBC"},
+		{SortByOpeningPrice, DESC, "BATBC", "BEXIMCO"},
+		{SortByPriceChange, ASC, "BRACBANK", "BATBC"},
+		{SortByPriceChange, DESC, "BATBC", "BRACBANK"},
+		{SortByPercentageChange, ASC, "BRACBANK", "BEXIMCO"},
+		{SortByPercentageChange, DESC, "BEXIMCO", "BRACBANK"},
+		{SortByVolumeOfShare, DESC, "BEXIMCO", "BATBC"},
+	}
+	for _, tt := range tests {
+		got, err := testCSE().GetLatestPrices(tt.by, tt.order)
+		if err != nil {
+			t.Fatalf("CSE.GetLatestPrices(%v, %v) error = %v", tt.by, tt.order, err)
+		}
+		if got[0].TradingCode != tt.first || got[len(got)-1].TradingCode != tt.last {
+			t.Errorf("CSE.GetLatestPrices(%v, %v) = %s...%s, want %s...%s", tt.by, tt.order, got[0].TradingCode, got[len(got)-1].TradingCode, tt.first, tt.last)
+		}
+	}
+}
+
+func TestCSEShare_PercentageChange(t *testing.T) {
+	tests := []struct {
+		name  string
+		share CSEShare
+		want  float64
+	}{
+		{"up", CSEShare{LTP: MustParseDecimal("110"), YCP: MustParseDecimal("100")}, 10},
+		{"down", CSEShare{LTP: MustParseDecimal("45.00"), YCP: MustParseDecimal("50.00")}, -10},
+		{"no ycp", CSEShare{LTP: MustParseDecimal("10")}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.share.PercentageChange(); got != tt.want {
+				t.Errorf("CSEShare.PercentageChange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_sortCse(t *testing.T) {
 	type args struct {
 		arr   []*CSEShare
@@ -104,7 +158,12 @@ func Test_sortCse(t *testing.T) {
 		{"", args{make([]*CSEShare, 0), SortByValue, DESC}, make([]*CSEShare, 0), false},
 		{"", args{make([]*CSEShare, 0), SortByVolumeOfShare, ASC}, make([]*CSEShare, 0), false},
 		{"", args{make([]*CSEShare, 0), SortByVolumeOfShare, DESC}, make([]*CSEShare, 0), false},
-		{"", args{make([]*CSEShare, 0), SortByPercentageChange, DESC}, nil, true},
+		{"", args{make([]*CSEShare, 0), SortByOpeningPrice, ASC}, make([]*CSEShare, 0), false},
+		{"", args{make([]*CSEShare, 0), SortByOpeningPrice, DESC}, make([]*CSEShare, 0), false},
+		{"", args{make([]*CSEShare, 0), SortByPriceChange, ASC}, make([]*CSEShare, 0), false},
+		{"", args{make([]*CSEShare, 0), SortByPercentageChange, DESC}, make([]*CSEShare, 0), false},
+		{"", args{make([]*CSEShare, 0), sortBy(100), DESC}, nil, true},
+		{"", args{make([]*CSEShare, 0), SortByLTP, sortOrder(2)}, nil, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -121,25 +180,138 @@ func Test_sortCse(t *testing.T) {
 }
 
 func TestCSE_GetMarketSummary(t *testing.T) {
+	got, err := testCSE().GetMarketSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "cse_market_summary", got)
+}
+
+func TestCSE_GetMarketSummary_missingCell(t *testing.T) {
+	page, err := ioutil.ReadFile(filepath.Join("testdata", "www.cse.com.bd", "market", "historical_market.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the second row of the historical market summary lost its date
+	page = []byte(strings.Replace(string(page), `<div id="market_tab_2">2020-10-14</div>`, "", 1))
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(page)
+	}))
+	defer ts.Close()
+
+	cse := NewCSE(WithBaseURL(ts.URL))
+	if _, err := cse.GetMarketSummary(); !errors.Is(err, ErrLayoutChanged) {
+		t.Errorf("GetMarketSummary() error = %v, want ErrLayoutChanged", err)
+	}
+	for _, e := range cse.Healthcheck(context.Background()).Endpoints {
+		if e.Name == "historical_market" && (e.Healthy || !errors.Is(e.Err, ErrLayoutChanged)) {
+			t.Errorf("Healthcheck() endpoint %s: healthy = %v, error = %v, want ErrLayoutChanged", e.Name, e.Healthy, e.Err)
+		}
+	}
+}
+
+func TestCSE_GetAllListedCompaniesContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := NewCSE()
+	_, err := c.GetAllListedCompaniesContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("CSE.GetAllListedCompaniesContext() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestCSE_GetLatestPrices_golden(t *testing.T) {
+	got, err := testCSE().GetLatestPrices(SortByTradingCode, ASC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "cse_latest_prices", got)
+}
+
+func TestCSE_GetMarketStatus(t *testing.T) {
+	got, err := testCSE().GetMarketStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "cse_market_status", got)
+}
+
+func TestCSE_GetAllWeeklyReports(t *testing.T) {
 	tests := []struct {
 		name    string
-		c       *CSE
-		want    *Summary
+		year    int
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{"2020", 2020, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := &CSE{}
-			got, err := c.GetMarketSummary()
+			got, err := testCSE().GetAllWeeklyReports(tt.year)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("CSE.GetMarketSummary() error = %v, wantErr %v", err, tt.wantErr)
-				return
+				t.Fatalf("CSE.GetAllWeeklyReports() error = %v, wantErr %v", err, tt.wantErr)
 			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("CSE.GetMarketSummary() = %v, want %v", got, tt.want)
+			if err == nil {
+				assertGolden(t, "cse_weekly_reports_"+tt.name, got)
 			}
 		})
 	}
 }
+
+func TestCSE_GetAllListedCompanies(t *testing.T) {
+	got, err := testCSE().GetAllListedCompanies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "cse_listed_companies", got)
+}
+
+func TestCSE_GetAllListedCompaniesByIndustry(t *testing.T) {
+	got, err := testCSE().GetAllListedCompaniesByIndustry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "cse_listed_companies_by_industry", got)
+}
+
+func TestCSE_GetAllListedCompaniesByCategory(t *testing.T) {
+	got, err := testCSE().GetAllListedCompaniesByCategory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "cse_listed_companies_by_category", got)
+}
+
+func TestCSE_GetPriceEarningRatio(t *testing.T) {
+	tests := []struct {
+		name    string
+		day     string
+		wantErr error
+	}{
+		{"trading day", "15", nil},
+		{"holiday", "16", ErrNoDataFound},
+		{"invalid date", "32", ErrInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testCSE().GetPriceEarningRatio(tt.day, "10", "2020")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CSE.GetPriceEarningRatio() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				assertGolden(t, "cse_price_earning_ratio", got)
+			}
+		})
+	}
+}
+
+func TestCSE_GetIndexSnapshot(t *testing.T) {
+	got, err := testCSE().GetIndexSnapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Indices) != 5 || got.Index("caspi") == nil || got.Index("DSEX") != nil {
+		t.Errorf("GetIndexSnapshot() indices = %+v", got.Indices)
+	}
+	assertGolden(t, "cse_index_snapshot", got)
+}
@@ -0,0 +1,330 @@
+package bdstockexchange
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxDecimalScale is the most digits a Decimal keeps after the decimal point
+const maxDecimalScale = 18
+
+// errDecimalSyntax is returned by ParseDecimal for a text which is not a decimal number
+var errDecimalSyntax = errors.New("invalid decimal syntax")
+
+// Decimal is an exact decimal number which keeps the precision the exchange printed it with,
+// ex: 12.30 is the 1230 units of the scale 2. The prices are in Taka, so a price of the scale 2 is exact to the paisa.
+// The zero value is 0. Decimal is a value type and its methods never change it.
+// The units are an int64, so a decimal of the scale 2 holds up to about 9.2e16. Add, Sub and Mul wrap around like
+// int64 if the result does not fit, CheckedAdd, CheckedSub and CheckedMul return an error instead
+type Decimal struct {
+	units int64
+	scale int32
+}
+
+// NewDecimal returns the decimal units / 10^scale, ex: NewDecimal(1230, 2) is 12.30.
+// A negative scale is taken as 0 and a scale over 18 is rounded to 18
+func NewDecimal(units int64, scale int) Decimal {
+	if scale < 0 {
+		scale = 0
+	}
+	if scale > maxDecimalScale {
+		return Decimal{units, int32(scale)}.Round(maxDecimalScale)
+	}
+	return Decimal{units: units, scale: int32(scale)}
+}
+
+// DecimalFromPaisa returns the decimal of the scale 2 in Taka of the paisa, ex: DecimalFromPaisa(110530) is 1105.30
+func DecimalFromPaisa(paisa int64) Decimal {
+	return NewDecimal(paisa, 2)
+}
+
+// DecimalFromFloat returns f rounded half away from zero to the scale, ex: DecimalFromFloat(1105.3, 2) is 1105.30.
+// A negative scale is taken as 0 and a scale over 18 as 18. It returns an error wrapping ErrInvalidArgument for NaN and ±Inf and strconv.ErrRange if f does not fit a Decimal
+// of the scale
+func DecimalFromFloat(f float64, scale int) (Decimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}, fmt.Errorf("%w: %v is not a decimal", ErrInvalidArgument, f)
+	}
+	if scale < 0 {
+		scale = 0
+	}
+	if scale > maxDecimalScale {
+		scale = maxDecimalScale
+	}
+	// the shortest text of a tiny float can have more decimals than a Decimal keeps, it is rounded to the most first
+	text := strconv.FormatFloat(f, 'f', -1, 64)
+	if i := strings.IndexByte(text, '.'); i >= 0 && len(text)-i-1 > maxDecimalScale {
+		text = strconv.FormatFloat(f, 'f', maxDecimalScale, 64)
+	}
+	d, err := ParseDecimal(text)
+	if err != nil {
+		return Decimal{}, err
+	}
+	d, ok := d.Round(scale).checkedRescale(scale)
+	if !ok {
+		return Decimal{}, fmt.Errorf("%w: %v of the scale %d", strconv.ErrRange, f, scale)
+	}
+	return d, nil
+}
+
+// ParseDecimal parses a decimal number as the exchanges print it, ex: "-1,105.30". The commas are ignored and
+// the scale of the decimal is the number of digits after the decimal point
+func ParseDecimal(text string) (Decimal, error) {
+	s := normalizeAmerican(strings.TrimSpace(text))
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	digits := s
+	scale := 0
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		digits = s[:i] + s[i+1:]
+		scale = len(s) - i - 1
+	}
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("%w: %q", errDecimalSyntax, text)
+	}
+	if scale > maxDecimalScale {
+		return Decimal{}, fmt.Errorf("%w: %q has more than %d decimals", errDecimalSyntax, text, maxDecimalScale)
+	}
+	if neg {
+		digits = "-" + digits
+	}
+	units, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("%w: %q", strconv.ErrRange, text)
+	}
+	return Decimal{units: units, scale: int32(scale)}, nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics if the text can not be parsed. It is meant for constants and tests
+func MustParseDecimal(text string) Decimal {
+	d, err := ParseDecimal(text)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Units returns the units of the decimal, which is the decimal multiplied by 10^Scale
+func (d Decimal) Units() int64 {
+	return d.units
+}
+
+// Scale returns the number of digits after the decimal point
+func (d Decimal) Scale() int {
+	return int(d.scale)
+}
+
+// Paisa returns the decimal in paisa, the hundredth of a Taka, rounded half away from zero
+func (d Decimal) Paisa() int64 {
+	return d.Round(2).rescale(2).units
+}
+
+// Float64 returns the nearest float64 of the decimal
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// IsZero returns true if the decimal is 0 of any scale
+func (d Decimal) IsZero() bool {
+	return d.units == 0
+}
+
+// Sign returns -1, 0 or 1 if the decimal is negative, zero or positive
+func (d Decimal) Sign() int {
+	switch {
+	case d.units < 0:
+		return -1
+	case d.units > 0:
+		return 1
+	}
+	return 0
+}
+
+// Neg returns -d
+func (d Decimal) Neg() Decimal {
+	return Decimal{-d.units, d.scale}
+}
+
+// Add returns d + x of the larger scale of the two. It wraps around if the sum does not fit, see CheckedAdd
+func (d Decimal) Add(x Decimal) Decimal {
+	scale := maxScale(d, x)
+	return Decimal{d.rescale(scale).units + x.rescale(scale).units, int32(scale)}
+}
+
+// Sub returns d - x of the larger scale of the two. It wraps around if the difference does not fit, see CheckedSub
+func (d Decimal) Sub(x Decimal) Decimal {
+	return d.Add(x.Neg())
+}
+
+// Mul returns d * x. The scale of the result is the sum of the scales, rounded to 18 if it is more.
+// It wraps around if the product does not fit, see CheckedMul
+func (d Decimal) Mul(x Decimal) Decimal {
+	return NewDecimal(d.units*x.units, int(d.scale+x.scale))
+}
+
+// CheckedAdd is like Add but returns an error wrapping strconv.ErrRange if the sum does not fit a Decimal
+func (d Decimal) CheckedAdd(x Decimal) (Decimal, error) {
+	scale := maxScale(d, x)
+	a, okA := d.checkedRescale(scale)
+	b, okB := x.checkedRescale(scale)
+	sum := a.units + b.units
+	if !okA || !okB || (a.units > 0 && b.units > 0 && sum < 0) || (a.units < 0 && b.units < 0 && sum >= 0) {
+		return Decimal{}, fmt.Errorf("%w: %s + %s", strconv.ErrRange, d, x)
+	}
+	return Decimal{sum, int32(scale)}, nil
+}
+
+// CheckedSub is like Sub but returns an error wrapping strconv.ErrRange if the difference does not fit a Decimal
+func (d Decimal) CheckedSub(x Decimal) (Decimal, error) {
+	if x.units == math.MinInt64 {
+		return Decimal{}, fmt.Errorf("%w: %s - %s", strconv.ErrRange, d, x)
+	}
+	return d.CheckedAdd(x.Neg())
+}
+
+// CheckedMul is like Mul but returns an error wrapping strconv.ErrRange if the product does not fit a Decimal
+func (d Decimal) CheckedMul(x Decimal) (Decimal, error) {
+	a, b := d.units, x.units
+	product := a * b
+	if a != 0 && (product/a != b || (a == -1 && b == math.MinInt64)) {
+		return Decimal{}, fmt.Errorf("%w: %s * %s", strconv.ErrRange, d, x)
+	}
+	return NewDecimal(product, int(d.scale+x.scale)), nil
+}
+
+// Cmp returns -1, 0 or 1 if d is less than, equal to or greater than x. It is exact for every pair of decimals,
+// even if their difference does not fit a Decimal
+func (d Decimal) Cmp(x Decimal) int {
+	scale := maxScale(d, x)
+	a, okA := d.checkedRescale(scale)
+	b, okB := x.checkedRescale(scale)
+	if !okA || !okB {
+		return d.bigUnits(scale).Cmp(x.bigUnits(scale))
+	}
+	switch {
+	case a.units < b.units:
+		return -1
+	case a.units > b.units:
+		return 1
+	}
+	return 0
+}
+
+// Equal returns true if d and x are the same number, whatever their scales are, ex: 12.3 is equal to 12.30
+func (d Decimal) Equal(x Decimal) bool {
+	return d.Cmp(x) == 0
+}
+
+// Round returns the decimal rounded half away from zero to the scale. A decimal with fewer digits is returned as it is
+func (d Decimal) Round(scale int) Decimal {
+	if scale < 0 {
+		scale = 0
+	}
+	if scale >= int(d.scale) {
+		return d
+	}
+	if int(d.scale)-scale > maxDecimalScale {
+		return Decimal{0, int32(scale)}
+	}
+	p := pow10(int(d.scale) - scale)
+	q, r := d.units/p, d.units%p
+	if r >= p/2+p%2 {
+		q++
+	} else if -r >= p/2+p%2 {
+		q--
+	}
+	return Decimal{q, int32(scale)}
+}
+
+// rescale returns the decimal with more digits after the decimal point. It never drops a digit and wraps around if
+// the units do not fit, see checkedRescale
+func (d Decimal) rescale(scale int) Decimal {
+	if scale <= int(d.scale) {
+		return d
+	}
+	return Decimal{d.units * pow10(scale-int(d.scale)), int32(scale)}
+}
+
+// checkedRescale is like rescale but returns false if the units do not fit an int64
+func (d Decimal) checkedRescale(scale int) (Decimal, bool) {
+	if scale <= int(d.scale) {
+		return d, true
+	}
+	p := pow10(scale - int(d.scale))
+	if d.units > math.MaxInt64/p || d.units < math.MinInt64/p {
+		return Decimal{}, false
+	}
+	return Decimal{d.units * p, int32(scale)}, true
+}
+
+// bigUnits returns the units of the decimal rescaled to the scale as a big.Int, which never overflows
+func (d Decimal) bigUnits(scale int) *big.Int {
+	u := big.NewInt(d.units)
+	if scale > int(d.scale) {
+		u.Mul(u, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale-int(d.scale))), nil))
+	}
+	return u
+}
+
+// String returns the decimal with all the digits of its scale, ex: "-1105.30"
+func (d Decimal) String() string {
+	abs := uint64(d.units)
+	if d.units < 0 {
+		abs = uint64(-d.units)
+	}
+	s := strconv.FormatUint(abs, 10)
+	if d.scale > 0 {
+		if len(s) <= int(d.scale) {
+			s = strings.Repeat("0", int(d.scale)-len(s)+1) + s
+		}
+		s = s[:len(s)-int(d.scale)] + "." + s[len(s)-int(d.scale):]
+	}
+	if d.units < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// MarshalJSON writes the decimal as a JSON number with all the digits of its scale, ex: 1105.30
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON reads the decimal from a JSON number or string. A null keeps the decimal as it is
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	text := string(data)
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+	v, err := ParseDecimal(text)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// maxScale returns the larger scale of a and b
+func maxScale(a, b Decimal) int {
+	if a.scale > b.scale {
+		return int(a.scale)
+	}
+	return int(b.scale)
+}
+
+// pow10 returns 10^n for 0 <= n <= 18
+func pow10(n int) int64 {
+	return int64(math.Pow10(n))
+}
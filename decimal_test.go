@@ -0,0 +1,178 @@
+package bdstockexchange
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    string
+		units   int64
+		scale   int
+		wantErr bool
+	}{
+		{"1105.30", "1105.30", 110530, 2, false},
+		{" 1,356,002,110 ", "1356002110", 1356002110, 0, false},
+		{"-0.4", "-0.4", -4, 1, false},
+		{"+.05", "0.05", 5, 2, false},
+		{"60.203", "60.203", 60203, 3, false},
+		{"", "0", 0, 0, true},
+		{"12.3.4", "0", 0, 0, true},
+		{"1e5", "0", 0, 0, true},
+		{"99999999999999999999", "0", 0, 0, true},
+		{"-9223372036854775808", "-9223372036854775808", math.MinInt64, 0, false},
+		{"9223372036854775808", "0", 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := ParseDecimal(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDecimal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.String() != tt.want || got.Units() != tt.units || got.Scale() != tt.scale {
+				t.Errorf("ParseDecimal() = %v (%d, %d), want %v (%d, %d)", got, got.Units(), got.Scale(), tt.want, tt.units, tt.scale)
+			}
+		})
+	}
+}
+
+func TestDecimal_arithmetic(t *testing.T) {
+	d := MustParseDecimal
+	tests := []struct {
+		name string
+		got  Decimal
+		want string
+	}{
+		{"add", d("0.1").Add(d("0.2")), "0.3"},
+		{"add scales", d("1105.3").Add(d("0.05")), "1105.35"},
+		{"sub", d("13.1").Sub(d("13.5")), "-0.4"},
+		{"mul", d("42.90").Mul(d("1000")), "42900.00"},
+		{"round half up", d("2.345").Round(2), "2.35"},
+		{"round half down", d("-2.345").Round(2), "-2.35"},
+		{"round less digits", d("2.3").Round(2), "2.3"},
+		{"from paisa", DecimalFromPaisa(-5), "-0.05"},
+		{"new decimal", NewDecimal(1230, 2), "12.30"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got.String() != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+
+	if !d("12.3").Equal(d("12.30")) || d("12.3").Cmp(d("12.31")) != -1 || d("-1").Sign() != -1 || !d("0.00").IsZero() {
+		t.Errorf("Decimal comparisons are wrong")
+	}
+	if got := d("1105.305").Paisa(); got != 110531 {
+		t.Errorf("Paisa() = %v, want 110531", got)
+	}
+	if got := d("0.1").Add(d("0.2")).Float64(); got != 0.3 {
+		t.Errorf("Float64() = %v, want 0.3", got)
+	}
+}
+
+func TestDecimal_overflow(t *testing.T) {
+	d := MustParseDecimal
+	max, min := NewDecimal(math.MaxInt64, 0), NewDecimal(math.MinInt64, 0)
+	tests := []struct {
+		name    string
+		op      func() (Decimal, error)
+		want    string
+		wantErr bool
+	}{
+		{"add", func() (Decimal, error) { return d("0.1").CheckedAdd(d("0.2")) }, "0.3", false},
+		{"add over max", func() (Decimal, error) { return max.CheckedAdd(d("1")) }, "", true},
+		{"add under min", func() (Decimal, error) { return min.CheckedAdd(d("-1")) }, "", true},
+		{"add rescale", func() (Decimal, error) { return d("92233720368547759").CheckedAdd(d("0.01")) }, "", true},
+		{"sub", func() (Decimal, error) { return d("13.1").CheckedSub(d("13.5")) }, "-0.4", false},
+		{"sub min", func() (Decimal, error) { return d("0").CheckedSub(min) }, "", true},
+		{"mul", func() (Decimal, error) { return d("42.90").CheckedMul(d("1000")) }, "42900.00", false},
+		{"mul over max", func() (Decimal, error) { return d("9223372036.854775807").CheckedMul(d("10000000000")) }, "", true},
+		{"mul min", func() (Decimal, error) { return d("-1").CheckedMul(min) }, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op()
+			if tt.wantErr {
+				if !errors.Is(err, strconv.ErrRange) {
+					t.Errorf("error = %v, want strconv.ErrRange", err)
+				}
+				return
+			}
+			if err != nil || got.String() != tt.want {
+				t.Errorf("got %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+
+	// the difference of the two does not fit, so the comparison must not be taken from Sub
+	if max.Cmp(min) != 1 || min.Cmp(max) != -1 || d("92233720368547759").Cmp(d("0.01")) != 1 || d("-0.01").Cmp(max) != -1 {
+		t.Errorf("Cmp() of decimals far apart is wrong")
+	}
+	if max.Equal(d("-0.01")) || !max.Equal(max) {
+		t.Errorf("Equal() of decimals far apart is wrong")
+	}
+}
+
+func TestDecimalFromFloat(t *testing.T) {
+	for _, tt := range []struct {
+		f     float64
+		scale int
+		want  string
+	}{
+		{1105.3, 2, "1105.30"},
+		{1105.305, 2, "1105.31"},
+		{1e-20, 2, "0.00"},
+		{1.0000000000000002e-7, 2, "0.00"},
+		{1.0000000000000002e-7, 7, "0.0000001"},
+		{-1e-20, 20, "0.000000000000000000"},
+		{1e10, 2, "10000000000.00"},
+	} {
+		if got, err := DecimalFromFloat(tt.f, tt.scale); err != nil || got.String() != tt.want {
+			t.Errorf("DecimalFromFloat(%v, %d) = %v, %v, want %v", tt.f, tt.scale, got, err, tt.want)
+		}
+	}
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := DecimalFromFloat(f, 2); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("DecimalFromFloat(%v, 2) error = %v, want ErrInvalidArgument", f, err)
+		}
+	}
+	for _, f := range []float64{1e30, 1e17} {
+		if _, err := DecimalFromFloat(f, 2); !errors.Is(err, strconv.ErrRange) {
+			t.Errorf("DecimalFromFloat(%v, 2) error = %v, want strconv.ErrRange", f, err)
+		}
+	}
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	in := struct {
+		LTP   Decimal `json:"ltp"`
+		Value Decimal `json:"value"`
+		Zero  Decimal `json:"zero"`
+	}{MustParseDecimal("1105.30"), MustParseDecimal("60.203"), Decimal{}}
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if want := `{"ltp":1105.30,"value":60.203,"zero":0}`; string(b) != want {
+		t.Errorf("json.Marshal() = %s, want %s", b, want)
+	}
+
+	out := in
+	out.LTP = Decimal{}
+	if err := json.Unmarshal([]byte(`{"ltp":"1,105.30","value":null}`), &out); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if out.LTP.String() != "1105.30" || out.Value != in.Value {
+		t.Errorf("json.Unmarshal() = %+v, want %+v", out, in)
+	}
+	if err := json.Unmarshal([]byte(`{"ltp":true}`), &out); err == nil {
+		t.Errorf("json.Unmarshal() of a bool error = nil")
+	}
+}
@@ -1,116 +1,118 @@
 package bdstockexchange
 
 import (
-	"errors"
+	"context"
 	"fmt"
-	"sort"
-	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
 )
 
 // DSE is a struct to access dse related methods
 type DSE struct {
+	client client
 }
 
-const (
-	idDSE = iota
-	tradingCodeDSE
-	ltpDSE
-	highDSE
-	lowDSE
-	closePriceDSE
-	ycpDSE
-	changeDSE
-	tradeDSE
-	valueDSE
-	volumeDSE
-)
+// dseShareColumns are the columns of the dse latest share price tables
+var dseShareColumns = []column{
+	{"id", []string{"#", "SL", "SL."}},
+	{"trading_code", []string{"TRADING CODE", "CODE"}},
+	{"ltp", []string{"LTP*", "LTP"}},
+	{"high", []string{"HIGH"}},
+	{"low", []string{"LOW"}},
+	{"close_p", []string{"CLOSEP*", "CLOSEP", "CLOSE PRICE"}},
+	{"ycp", []string{"YCP*", "YCP"}},
+	{"change", []string{"CHANGE"}},
+	{"trade", []string{"TRADE"}},
+	{"value", []string{"VALUE (mn)", "VALUE (IN MN)", "VALUE"}},
+	{"volume", []string{"VOLUME"}},
+}
+
+// dsePercentageColumns are the columns of the dse latest share price table sorted by percentage change
+var dsePercentageColumns = []column{
+	{"id", []string{"#", "SL", "SL."}},
+	{"trading_code", []string{"TRADING CODE", "CODE"}},
+	{"ltp", []string{"LTP*", "LTP"}},
+	{"high", []string{"HIGH"}},
+	{"low", []string{"LOW"}},
+	{"close_p", []string{"CLOSEP*", "CLOSEP", "CLOSE PRICE"}},
+	{"ycp", []string{"YCP*", "YCP"}},
+	{"percentage_change", []string{"% CHANGE", "%CHANGE", "CHANGE (%)"}},
+	{"trade", []string{"TRADE"}},
+	{"value", []string{"VALUE (mn)", "VALUE (IN MN)", "VALUE"}},
+	{"volume", []string{"VOLUME"}},
+}
 
-// NewDSE returns new DSE object
-func NewDSE() *DSE {
-	return new(DSE)
+// NewDSE returns new DSE object configured with the given options
+func NewDSE(opts ...Option) *DSE {
+	return &DSE{client: newClient(dseBaseURL, opts)}
+}
+
+// url returns the full url of a dse page
+func (d *DSE) url(path string) string {
+	return d.client.url(dseBaseURL, path)
 }
 
 // DSEShare is a model for a single company's latest price data provided by the dse website
 type DSEShare struct {
 	ID          int     `json:"id"`
 	TradingCode string  `json:"trading_code"`
-	LTP         float64 `json:"ltp"`
-	High        float64 `json:"high"`
-	Low         float64 `json:"low"`
-	CloseP      float64 `json:"close_p"`
-	YCP         float64 `json:"ycp"`
-	Change      float64 `json:"change"`
+	LTP         Decimal `json:"ltp"`
+	High        Decimal `json:"high"`
+	Low         Decimal `json:"low"`
+	CloseP      Decimal `json:"close_p"`
+	YCP         Decimal `json:"ycp"`
+	Change      Decimal `json:"change"`
 	Trade       int64   `json:"trade"`
-	ValueInMN   float64 `json:"value"`
+	ValueInMN   Decimal `json:"value"`
 	Volume      int64   `json:"volume"`
+	// Category is the group of the share. It is only set by GetLatestPricesByCategory
+	Category string `json:"category,omitempty"`
 }
 
-func getDSELatestPrices(url string) ([]*DSEShare, error) {
+func (d *DSE) getLatestPrices(ctx context.Context, url string) ([]*DSEShare, error) {
 	latestShares := make([]*DSEShare, 0)
 	// Request the HTML page.
 	if url == "" {
-		url = "https://www.dsebd.org/latest_share_price_scroll_l.php"
+		url = d.url("/latest_share_price_scroll_l.php")
 	}
-	doc, err := htmlquery.LoadURL(url)
+	doc, err := d.client.loadURL(ctx, url)
 	if err != nil {
 		return nil, err
 	}
-	tab, _ := htmlquery.QueryAll(doc, `/html/body/div[2]/section/div/div[3]/div[1]/div[2]/div[1]`)
-	for _, v := range tab {
-		tbody, err := htmlquery.QueryAll(v, "//tbody")
-		if err != nil {
-			return nil, err
-		}
-		for _, v := range tbody {
-			td, err := htmlquery.QueryAll(v, "//tr //td")
-			if err != nil {
-				return nil, err
-			}
-			s := &DSEShare{}
-			for index, v := range td {
-				switch index {
-				case idDSE:
-					s.ID = toInt(htmlquery.InnerText(v))
-					break
-				case tradingCodeDSE:
-					s.TradingCode = strings.TrimSpace(htmlquery.InnerText(v))
-					break
-				case ltpDSE:
-					s.LTP = toFloat64(htmlquery.InnerText(v))
-					break
-				case highDSE:
-					s.High = toFloat64(htmlquery.InnerText(v))
-					break
-				case lowDSE:
-					s.Low = toFloat64(htmlquery.InnerText(v))
-					break
-				case closePriceDSE:
-					s.CloseP = toFloat64(htmlquery.InnerText(v))
-					break
-				case ycpDSE:
-					s.YCP = toFloat64(htmlquery.InnerText(v))
-					break
-				case changeDSE:
-					s.Change = toFloat64(htmlquery.InnerText(v))
-					break
-				case tradeDSE:
-					s.Trade = toInt64(htmlquery.InnerText(v))
-					break
-				case valueDSE:
-					s.ValueInMN = toFloat64(htmlquery.InnerText(v))
-					break
-				case volumeDSE:
-					s.Volume = toInt64(htmlquery.InnerText(v))
-					break
-				}
 
-			}
+	t, err := findTable(doc, url, dseShareColumns)
+	if err != nil {
+		return nil, err
+	}
 
-			latestShares = append(latestShares, s)
+	p := &cellParser{url: url}
+	for row, cells := range t.rows {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
+		p.row = row
+		s := &DSEShare{
+			ID:          p.int("id", t.text(cells, "id")),
+			TradingCode: t.text(cells, "trading_code"),
+			LTP:         p.decimal("ltp", t.text(cells, "ltp")),
+			High:        p.decimal("high", t.text(cells, "high")),
+			Low:         p.decimal("low", t.text(cells, "low")),
+			CloseP:      p.decimal("close_p", t.text(cells, "close_p")),
+			YCP:         p.decimal("ycp", t.text(cells, "ycp")),
+			Change:      p.decimal("change", t.text(cells, "change")),
+			Trade:       p.int64("trade", t.text(cells, "trade")),
+			ValueInMN:   p.decimal("value", t.text(cells, "value")),
+			Volume:      p.int64("volume", t.text(cells, "volume")),
+		}
+		if p.err != nil {
+			return nil, p.err
+		}
+
+		latestShares = append(latestShares, s)
 	}
 	return latestShares, nil
 }
@@ -119,16 +121,24 @@ func getDSELatestPrices(url string) ([]*DSEShare, error) {
 // It takes a category name, by which field the array should be sorted ex: SortByTradingCode and sort order ex: ASC
 // It will return an error for if user tries to sort with a non existing file in the DSEShare model or invalid category name or invalid sort order
 func (d *DSE) GetLatestPricesByCategory(categoryName string, by sortBy, order sortOrder) ([]*DSEShare, error) {
+	return d.GetLatestPricesByCategoryContext(context.Background(), categoryName, by, order)
+}
+
+// GetLatestPricesByCategoryContext is like GetLatestPricesByCategory but takes a context to cancel the request or stop the parsing
+func (d *DSE) GetLatestPricesByCategoryContext(ctx context.Context, categoryName string, by sortBy, order sortOrder) ([]*DSEShare, error) {
 	categoryNameCap := strings.ToUpper(categoryName)
 	if !isValidCategoryName(categoryNameCap) {
-		return nil, errInvalidGroupName
+		return nil, ErrInvalidGroupName
 	}
 
-	url := fmt.Sprintf("https://www.dsebd.org/latest_share_price_all_group.php?group=%s", categoryNameCap)
-	arr, err := getDSELatestPrices(url)
+	url := d.url(fmt.Sprintf("/latest_share_price_all_group.php?group=%s", categoryNameCap))
+	arr, err := d.getLatestPrices(ctx, url)
 	if err != nil {
 		return nil, err
 	}
+	for _, s := range arr {
+		s.Category = categoryNameCap
+	}
 
 	arr, err = sortDse(arr, by, order)
 
@@ -139,7 +149,12 @@ func (d *DSE) GetLatestPricesByCategory(categoryName string, by sortBy, order so
 // It takes by which field the array should be sorted ex: SortByTradingCode and sort order ex: ASC
 // It will return an error for if user tries to sort with a non existing file in the DSEShare model or invalid category name or invalid sort order
 func (d *DSE) GetLatestPrices(by sortBy, order sortOrder) ([]*DSEShare, error) {
-	arr, err := getDSELatestPrices("https://www.dsebd.org/latest_share_price_scroll_l.php")
+	return d.GetLatestPricesContext(context.Background(), by, order)
+}
+
+// GetLatestPricesContext is like GetLatestPrices but takes a context to cancel the request or stop the parsing
+func (d *DSE) GetLatestPricesContext(ctx context.Context, by sortBy, order sortOrder) ([]*DSEShare, error) {
+	arr, err := d.getLatestPrices(ctx, d.url("/latest_share_price_scroll_l.php"))
 	if err != nil {
 		return nil, err
 	}
@@ -147,184 +162,71 @@ func (d *DSE) GetLatestPrices(by sortBy, order sortOrder) ([]*DSEShare, error) {
 	return arr, err
 }
 
+// sortDse sorts the shares by the field in the order
 func sortDse(arr []*DSEShare, by sortBy, order sortOrder) ([]*DSEShare, error) {
-	if order != ASC && order != DESC {
-		return nil, errors.New("order param is not valid. put a ASC or DESC as order param")
-	}
-	switch by {
-	case SortByTradingCode:
-		if order == ASC {
-			return arr, nil
-		}
-		sort.Slice(arr, func(i, j int) bool {
-			return arr[i].TradingCode > arr[j].TradingCode
-		})
-		return arr, nil
-	case SortByHighPrice:
-		if order == ASC {
-			sort.Slice(arr, func(i, j int) bool {
-				return arr[i].High < arr[j].High
-			})
-			return arr, nil
-		}
-		sort.Slice(arr, func(i, j int) bool {
-			return arr[i].High > arr[j].High
-		})
-		return arr, nil
-	case SortByLTP:
-		if order == ASC {
-			sort.Slice(arr, func(i, j int) bool {
-				return arr[i].LTP < arr[j].LTP
-			})
-			return arr, nil
-		}
-		sort.Slice(arr, func(i, j int) bool {
-			return arr[i].LTP > arr[j].LTP
-		})
-		return arr, nil
-	case SortByLowPrice:
-		if order == ASC {
-			sort.Slice(arr, func(i, j int) bool {
-				return arr[i].Low < arr[j].Low
-			})
-			return arr, nil
-		}
-		sort.Slice(arr, func(i, j int) bool {
-			return arr[i].Low > arr[j].Low
-		})
-		return arr, nil
-	case SortByNumberOfTrades:
-		if order == ASC {
-			sort.Slice(arr, func(i, j int) bool {
-				return arr[i].Trade < arr[j].Trade
-			})
-			return arr, nil
-		}
-		sort.Slice(arr, func(i, j int) bool {
-			return arr[i].Trade > arr[j].Trade
-		})
-		return arr, nil
-	case SortByPriceChange:
-		if order == ASC {
-			sort.Slice(arr, func(i, j int) bool {
-				return arr[i].Change < arr[j].Change
-			})
-			return arr, nil
-		}
-		sort.Slice(arr, func(i, j int) bool {
-			return arr[i].Change > arr[j].Change
-		})
-		return arr, nil
-	case SortByValue:
-		if order == ASC {
-			sort.Slice(arr, func(i, j int) bool {
-				return arr[i].ValueInMN < arr[j].ValueInMN
-			})
-			return arr, nil
-		}
-		sort.Slice(arr, func(i, j int) bool {
-			return arr[i].ValueInMN > arr[j].ValueInMN
-		})
-		return arr, nil
-	case SortByVolumeOfShare:
-		if order == ASC {
-			sort.Slice(arr, func(i, j int) bool {
-				return arr[i].Volume < arr[j].Volume
-			})
-			return arr, nil
-		}
-		sort.Slice(arr, func(i, j int) bool {
-			return arr[i].Volume > arr[j].Volume
-		})
-		return arr, nil
-	case SortByYCP:
-		if order == ASC {
-			sort.Slice(arr, func(i, j int) bool {
-				return arr[i].YCP < arr[j].YCP
-			})
-			return arr, nil
-		}
-		sort.Slice(arr, func(i, j int) bool {
-			return arr[i].YCP > arr[j].YCP
-		})
-		return arr, nil
-	default:
-		return nil, errors.New("sorting with the given sort by param is not possible. try another one")
-	}
+	return Query().SortBy(by, order).DSEShares(arr)
 }
 
 // LatestPricesWithPercentage ...
 type LatestPricesWithPercentage struct {
 	ID               int     `json:"id"`
 	TradingCode      string  `json:"trading_code"`
-	LTP              float64 `json:"ltp"`
-	High             float64 `json:"high"`
-	Low              float64 `json:"low"`
-	CloseP           float64 `json:"close_p"`
-	YCP              float64 `json:"ycp"`
+	LTP              Decimal `json:"ltp"`
+	High             Decimal `json:"high"`
+	Low              Decimal `json:"low"`
+	CloseP           Decimal `json:"close_p"`
+	YCP              Decimal `json:"ycp"`
 	PercentageChange float64 `json:"percentage_change"`
 	Trade            int64   `json:"trade"`
-	ValueInMN        float64 `json:"value"`
+	ValueInMN        Decimal `json:"value"`
 	Volume           int64   `json:"volume"`
 }
 
 // GetLatestPricesSortedByPercentageChange ...
 func (d *DSE) GetLatestPricesSortedByPercentageChange() ([]*LatestPricesWithPercentage, error) {
-	latestPricesWithPercentage := make([]*LatestPricesWithPercentage, 0)
-	doc, err := htmlquery.LoadURL("https://www.dsebd.org/latest_share_price_all_by_change.php")
+	return d.GetLatestPricesSortedByPercentageChangeContext(context.Background())
+}
+
+// GetLatestPricesSortedByPercentageChangeContext is like GetLatestPricesSortedByPercentageChange but takes a context to cancel the request or stop the parsing
+func (d *DSE) GetLatestPricesSortedByPercentageChangeContext(ctx context.Context) ([]*LatestPricesWithPercentage, error) {
+	url := d.url("/latest_share_price_all_by_change.php")
+	doc, err := d.client.loadURL(ctx, url)
 	if err != nil {
 		return nil, err
 	}
 
-	tr, err := htmlquery.QueryAll(doc, "//tr")
+	t, err := findTable(doc, url, dsePercentageColumns)
 	if err != nil {
 		return nil, err
 	}
-	for i, t := range tr {
-		if i == 0 {
-			continue
-		}
-		td, err := htmlquery.QueryAll(t, "//td")
-		if err != nil {
+
+	return readPercentageRows(ctx, t, url)
+}
+
+// readPercentageRows parses the rows of a table with the dsePercentageColumns
+func readPercentageRows(ctx context.Context, t *table, url string) ([]*LatestPricesWithPercentage, error) {
+	latestPricesWithPercentage := make([]*LatestPricesWithPercentage, 0)
+	p := &cellParser{url: url}
+	for row, cells := range t.rows {
+		if err := ctx.Err(); err != nil {
 			return nil, err
 		}
-		s := &LatestPricesWithPercentage{}
-		for index, v := range td {
-			switch index {
-			case 0:
-				s.ID = toInt(htmlquery.InnerText(v))
-				break
-			case 1:
-				s.TradingCode = strings.TrimSpace(htmlquery.InnerText(v))
-				break
-			case 2:
-				s.LTP = toFloat64(htmlquery.InnerText(v))
-				break
-			case 3:
-				s.High = toFloat64(htmlquery.InnerText(v))
-				break
-			case 4:
-				s.Low = toFloat64(htmlquery.InnerText(v))
-				break
-			case 5:
-				s.CloseP = toFloat64(htmlquery.InnerText(v))
-				break
-			case 6:
-				s.YCP = toFloat64(htmlquery.InnerText(v))
-				break
-			case 7:
-				s.PercentageChange = toFloat64(htmlquery.InnerText(v))
-				break
-			case 8:
-				s.Trade = toInt64(htmlquery.InnerText(v))
-				break
-			case 9:
-				s.ValueInMN = toFloat64(htmlquery.InnerText(v))
-				break
-			case 10:
-				s.Volume = toInt64(htmlquery.InnerText(v))
-				break
-			}
+		p.row = row
+		s := &LatestPricesWithPercentage{
+			ID:               p.int("id", t.text(cells, "id")),
+			TradingCode:      t.text(cells, "trading_code"),
+			LTP:              p.decimal("ltp", t.text(cells, "ltp")),
+			High:             p.decimal("high", t.text(cells, "high")),
+			Low:              p.decimal("low", t.text(cells, "low")),
+			CloseP:           p.decimal("close_p", t.text(cells, "close_p")),
+			YCP:              p.decimal("ycp", t.text(cells, "ycp")),
+			PercentageChange: p.float64("percentage_change", strings.Replace(t.text(cells, "percentage_change"), "%", "", -1)),
+			Trade:            p.int64("trade", t.text(cells, "trade")),
+			ValueInMN:        p.decimal("value", t.text(cells, "value")),
+			Volume:           p.int64("volume", t.text(cells, "volume")),
+		}
+		if p.err != nil {
+			return nil, p.err
 		}
 		latestPricesWithPercentage = append(latestPricesWithPercentage, s)
 	}
@@ -338,11 +240,18 @@ type DseMarketStatus struct {
 		Date string
 		Time string
 	}
+	// LastUpdatedAt is LastUpdatedOn parsed in the Dhaka location
+	LastUpdatedAt time.Time
 }
 
 // GetMarketStatus returns the DseMarketStatus with is open/close and last market update date time
 func (d *DSE) GetMarketStatus() (*DseMarketStatus, error) {
-	doc, err := htmlquery.LoadURL("https://www.dsebd.org/")
+	return d.GetMarketStatusContext(context.Background())
+}
+
+// GetMarketStatusContext is like GetMarketStatus but takes a context to cancel the request or stop the parsing
+func (d *DSE) GetMarketStatusContext(ctx context.Context) (*DseMarketStatus, error) {
+	doc, err := d.client.loadURL(ctx, d.url("/"))
 	if err != nil {
 		return nil, err
 	}
@@ -351,6 +260,9 @@ func (d *DSE) GetMarketStatus() (*DseMarketStatus, error) {
 		return nil, err
 	}
 
+	if isOpenNode == nil {
+		return nil, errLayoutChanged(d.url("/"), "market status")
+	}
 	isOpenText := htmlquery.InnerText(isOpenNode)
 
 	isOpen := false
@@ -359,35 +271,48 @@ func (d *DSE) GetMarketStatus() (*DseMarketStatus, error) {
 		isOpen = true
 	}
 
-	dateTimeNode, err := htmlquery.Query(doc, `/html/body/div[2]/section/div/div[1]/div/h2`)
+	date, clock, err := splitLastUpdate(htmlquery.FindOne(doc, `//h2[contains(., "Last update on")]`), d.url("/"))
 	if err != nil {
 		return nil, err
 	}
-
-	dateTimeText := htmlquery.InnerText(dateTimeNode)
-
-	splitDateTime := strings.Split(dateTimeText, "Last update on ")[1]
-	dateTime := strings.Split(splitDateTime, " at ")
-	date := dateTime[0]
-	time := dateTime[1]
+	p := &cellParser{url: d.url("/")}
 
 	dseMarketStatus := &DseMarketStatus{
 		IsOpen: isOpen,
 		LastUpdatedOn: struct {
 			Date string
 			Time string
-		}{Date: date, Time: time},
+		}{Date: date, Time: clock},
+		LastUpdatedAt: p.date("last_updated_at", date+" "+clock, dseLastUpdateLayout),
+	}
+	if p.err != nil {
+		return nil, p.err
 	}
 
 	return dseMarketStatus, nil
 }
 
+// dseSummaryColumns are the totals of the dse home page. They are printed in rows of labels each followed by a row
+// of their values
+var dseSummaryColumns = []column{
+	{"total_trade", []string{"Total Trade"}},
+	{"total_volume", []string{"Total Volume"}},
+	{"total_value", []string{"Total Value in Taka (mn)", "Total Value (mn)"}},
+	{"issues_advanced", []string{"Issues Advanced"}},
+	{"issues_declined", []string{"Issues Declined"}},
+	{"issues_unchanged", []string{"Issues Unchanged"}},
+	{"market_cap", []string{"Total Market Capitalization in Taka (mn)", "Total Market Cap. in Taka (mn)", "Market Capitalization (mn)"}},
+	{"equity_market_cap", []string{"Equity Market Capitalization in Taka (mn)", "Equity Market Cap. in Taka (mn)"}},
+}
+
 // MarketSummary holds the data for market summary like DSEX index details and total trades and so on
 type MarketSummary struct {
 	LastUpdatedOn struct {
 		Date string `json:"date"`
 		Time string `json:"time"`
 	} `json:"last_updated_on"`
+	// LastUpdatedAt is LastUpdatedOn parsed in the Dhaka location
+	LastUpdatedAt time.Time `json:"last_updated_at"`
 
 	DseX struct {
 		DSEXIndex                 float64 `json:"dsex_index"`
@@ -407,217 +332,154 @@ type MarketSummary struct {
 		DSESIndexChangePercentage float64 `json:"dses_index_change_percentage"`
 	} `json:"dses"`
 
-	TotalTrade     int64   `json:""`
-	TotalValueInMN float64 `json:""`
-	TotalVolume    int64   `json:""`
+	TotalTrade     int64   `json:"total_trade"`
+	TotalValueInMN Decimal `json:"total_value"`
+	TotalVolume    int64   `json:"total_volume"`
+
+	// MarketCapInMN is the market capitalization of all the securities and EquityMarketCapInMN of the equities only
+	MarketCapInMN       Decimal `json:"market_cap"`
+	EquityMarketCapInMN Decimal `json:"equity_market_cap"`
 
-	IssuesAdvanced  int32 `json:""`
-	IssuesDeclined  int32 `json:""`
-	IssuesUnchanged int32 `json:""`
+	IssuesAdvanced  int32 `json:"issues_advanced"`
+	IssuesDeclined  int32 `json:"issues_declined"`
+	IssuesUnchanged int32 `json:"issues_unchanged"`
 }
 
 // GetMarketSummary returns the last updated market summary data
 func (d *DSE) GetMarketSummary() (*MarketSummary, error) {
-	doc, err := htmlquery.LoadURL("https://www.dsebd.org/")
+	return d.GetMarketSummaryContext(context.Background())
+}
+
+// GetMarketSummaryContext is like GetMarketSummary but takes a context to cancel the request or stop the parsing
+func (d *DSE) GetMarketSummaryContext(ctx context.Context) (*MarketSummary, error) {
+	url := d.url("/")
+	doc, err := d.client.loadURL(ctx, url)
 	if err != nil {
 		return nil, err
 	}
 
-	dateTimeNode, err := htmlquery.Query(doc, `/html/body/div[2]/section/div/div[1]/div/h2`)
+	date, clock, err := splitLastUpdate(htmlquery.FindOne(doc, `//h2[contains(., "Last update on")]`), url)
 	if err != nil {
 		return nil, err
 	}
 
-	dateTimeText := htmlquery.InnerText(dateTimeNode)
-
-	splitDateTime := strings.Split(dateTimeText, "Last update on ")[1]
-	dateTime := strings.Split(splitDateTime, " at ")
-	date := dateTime[0]
-	time := dateTime[1]
-
+	p := &cellParser{url: url}
 	dseMarketSummary := &MarketSummary{}
 	dseMarketSummary.LastUpdatedOn.Date = date
-	dseMarketSummary.LastUpdatedOn.Time = time
+	dseMarketSummary.LastUpdatedOn.Time = clock
+	dseMarketSummary.LastUpdatedAt = p.date("last_updated_at", date+" "+clock, dseLastUpdateLayout)
 
-	//var aggErr error
-	node, err := htmlquery.Query(doc, `/html/body/div[2]/section/div/div[1]/div/div[1]`)
-	if err != nil {
-		return nil, err
-	}
-	divNode, err := htmlquery.QueryAll(node, "//div")
+	indices, totals, err := readSummaryRows(doc, url)
 	if err != nil {
 		return nil, err
 	}
 
-	for index, v := range divNode {
-		switch index {
-		case 2:
-			dsexIndexString := htmlquery.InnerText(v)
-			dsexIndex, err := strconv.ParseFloat(strings.TrimSpace(dsexIndexString), 64)
-			if err != nil {
-				return nil, err
-			}
-			dseMarketSummary.DseX.DSEXIndex = dsexIndex
-		case 3:
-			dsexIndexChangeString := htmlquery.InnerText(v)
-			dsexIndexChange, err := strconv.ParseFloat(strings.TrimSpace(dsexIndexChangeString), 64)
-			if err != nil {
-				return nil, err
-			}
-			dseMarketSummary.DseX.DSEXIndexChange = dsexIndexChange
-		case 4:
-			dsexIndexChangePString := htmlquery.InnerText(v)
-			dsexIndexChangeP, err := strconv.ParseFloat(strings.TrimSpace(strings.Replace(dsexIndexChangePString, "%", "", -1)), 64)
-			if err != nil {
-				return nil, err
-			}
-			dseMarketSummary.DseX.DSEXIndexChangePercentage = dsexIndexChangeP
+	for _, index := range []struct {
+		name                      string
+		value, change, percentage *float64
+	}{
+		{"DSEX", &dseMarketSummary.DseX.DSEXIndex, &dseMarketSummary.DseX.DSEXIndexChange, &dseMarketSummary.DseX.DSEXIndexChangePercentage},
+		{"DSES", &dseMarketSummary.DseS.DSESIndex, &dseMarketSummary.DseS.DSESIndexChange, &dseMarketSummary.DseS.DSESIndexChangePercentage},
+		{"DS30", &dseMarketSummary.Ds30.DS30Index, &dseMarketSummary.Ds30.DS30IndexChange, &dseMarketSummary.Ds30.DS30IndexChangePercentage},
+	} {
+		cells, ok := indices[index.name]
+		if !ok {
+			return nil, errLayoutChanged(url, index.name+" index")
 		}
+		column := strings.ToLower(index.name) + "_index"
+		*index.value = p.float64(column, cells[0])
+		*index.change = p.float64(column+"_change", cells[1])
+		*index.percentage = p.float64(column+"_change_percentage", strings.Replace(cells[2], "%", "", -1))
 	}
 
-	// DSES
-	node, err = htmlquery.Query(doc, `/html/body/div[2]/section/div/div[1]/div/div[2]`)
-	if err != nil {
-		return nil, err
-	}
-	divNode, err = htmlquery.QueryAll(node, "//div")
-	if err != nil {
-		return nil, err
-	}
+	dseMarketSummary.TotalTrade = p.int64("total_trade", totals.text(totals.rows[0], "total_trade"))
+	dseMarketSummary.TotalVolume = p.int64("total_volume", totals.text(totals.rows[0], "total_volume"))
+	dseMarketSummary.TotalValueInMN = p.decimal("total_value", totals.text(totals.rows[0], "total_value"))
+	dseMarketSummary.MarketCapInMN = p.decimal("market_cap", totals.text(totals.rows[0], "market_cap"))
+	dseMarketSummary.EquityMarketCapInMN = p.decimal("equity_market_cap", totals.text(totals.rows[0], "equity_market_cap"))
+	dseMarketSummary.IssuesAdvanced = int32(p.int64("issues_advanced", totals.text(totals.rows[0], "issues_advanced")))
+	dseMarketSummary.IssuesDeclined = int32(p.int64("issues_declined", totals.text(totals.rows[0], "issues_declined")))
+	dseMarketSummary.IssuesUnchanged = int32(p.int64("issues_unchanged", totals.text(totals.rows[0], "issues_unchanged")))
 
-	for index, v := range divNode {
-		switch index {
-		case 2:
-			dsesIndexString := htmlquery.InnerText(v)
-			dsesIndex, err := strconv.ParseFloat(strings.TrimSpace(dsesIndexString), 64)
-			if err != nil {
-				return nil, err
-			}
-			dseMarketSummary.DseS.DSESIndex = dsesIndex
-		case 3:
-			dsesIndexChangeString := htmlquery.InnerText(v)
-			dsesIndexChange, err := strconv.ParseFloat(strings.TrimSpace(dsesIndexChangeString), 64)
-			if err != nil {
-				return nil, err
-			}
-			dseMarketSummary.DseS.DSESIndexChange = dsesIndexChange
-		case 4:
-			dsesIndexChangePString := htmlquery.InnerText(v)
-			dsesIndexChangeP, err := strconv.ParseFloat(strings.TrimSpace(strings.Replace(dsesIndexChangePString, "%", "", -1)), 64)
-			if err != nil {
-				return nil, err
-			}
-			dseMarketSummary.DseS.DSESIndexChangePercentage = dsesIndexChangeP
-		}
+	if p.err != nil {
+		return nil, p.err
 	}
 
-	// DS30
-	node, err = htmlquery.Query(doc, `/html/body/div[2]/section/div/div[1]/div/div[3]`)
-	if err != nil {
-		return nil, err
-	}
-	divNode, err = htmlquery.QueryAll(node, "//div")
-	if err != nil {
-		return nil, err
-	}
+	return dseMarketSummary, nil
+}
 
-	for index, v := range divNode {
-		switch index {
-		case 2:
-			ds30IndexString := htmlquery.InnerText(v)
-			ds30Index, err := strconv.ParseFloat(strings.TrimSpace(ds30IndexString), 64)
-			if err != nil {
-				return nil, err
+// readSummaryRows reads the rows of the dse home page summary. An index row is printed as its name, value, change and
+// percentage change, ex: "DSEX Index", "4,987.47", "12.35" and "0.25%", and is returned by the name, ex: DSEX.
+// The totals are returned as a table of a single row with the dseSummaryColumns
+func readSummaryRows(doc *html.Node, url string) (map[string][]string, *table, error) {
+	rows := make([][]*html.Node, 0)
+	for _, row := range htmlquery.Find(doc, `//div[contains(@class, "midrow")]`) {
+		cells := make([]*html.Node, 0)
+		for c := row.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && c.Data == "div" {
+				cells = append(cells, c)
 			}
-			dseMarketSummary.Ds30.DS30Index = ds30Index
-		case 3:
-			ds30IndexChangeString := htmlquery.InnerText(v)
-			ds30IndexChange, err := strconv.ParseFloat(strings.TrimSpace(ds30IndexChangeString), 64)
-			if err != nil {
-				return nil, err
-			}
-			dseMarketSummary.Ds30.DS30IndexChange = ds30IndexChange
-		case 4:
-			ds30IndexChangePString := htmlquery.InnerText(v)
-			ds30IndexChangeP, err := strconv.ParseFloat(strings.TrimSpace(strings.Replace(ds30IndexChangePString, "%", "", -1)), 64)
-			if err != nil {
-				return nil, err
-			}
-			dseMarketSummary.Ds30.DS30IndexChangePercentage = ds30IndexChangeP
 		}
+		rows = append(rows, cells)
 	}
-
-	// Total Trade, Volumes, Value
-	node, err = htmlquery.Query(doc, `/html/body/div[2]/section/div/div[1]/div/div[5]`)
-	if err != nil {
-		return nil, err
-	}
-	divNode, err = htmlquery.QueryAll(node, "//div")
-	if err != nil {
-		return nil, err
+	if len(rows) == 0 {
+		return nil, nil, errLayoutChanged(url, "summary section")
 	}
 
-	for index, v := range divNode {
-		switch index {
-		case 1:
-			totalTradeString := htmlquery.InnerText(v)
-			totalTrade, err := strconv.ParseInt(strings.TrimSpace(totalTradeString), 10, 64)
-			if err != nil {
-				return nil, err
-			}
-			dseMarketSummary.TotalTrade = totalTrade
-		case 2:
-			totalVolumeString := htmlquery.InnerText(v)
-			totalVolume, err := strconv.ParseInt(strings.TrimSpace(totalVolumeString), 10, 64)
-			if err != nil {
-				return nil, err
-			}
-			dseMarketSummary.TotalVolume = totalVolume
-		case 3:
-			totalValueString := htmlquery.InnerText(v)
-			totalValue, err := strconv.ParseFloat(strings.TrimSpace(strings.Replace(totalValueString, "%", "", -1)), 64)
-			if err != nil {
-				return nil, err
+	isLabel := func(cell *html.Node) bool {
+		return strings.IndexFunc(htmlquery.InnerText(cell), unicode.IsLetter) >= 0
+	}
+	indices := make(map[string][]string)
+	var headers []string
+	var values []*html.Node
+	for i := 0; i < len(rows); i++ {
+		row := rows[i]
+		if len(row) == 0 {
+			continue
+		}
+		name := normalizeHeader(htmlquery.InnerText(row[0]))
+		if len(row) == 4 && strings.HasSuffix(name, " INDEX") {
+			cells := make([]string, 0, 3)
+			for _, c := range row[1:] {
+				cells = append(cells, strings.TrimSpace(htmlquery.InnerText(c)))
 			}
-			dseMarketSummary.Ds30.DS30IndexChangePercentage = totalValue
+			indices[strings.TrimSuffix(name, " INDEX")] = cells
+			continue
+		}
+		if i+1 == len(rows) || len(rows[i+1]) != len(row) || !isLabel(row[0]) || isLabel(rows[i+1][0]) {
+			continue
+		}
+		for j, c := range row {
+			headers = append(headers, normalizeHeader(htmlquery.InnerText(c)))
+			values = append(values, rows[i+1][j])
 		}
+		i++
 	}
 
-	// Total issues
-	node, err = htmlquery.Query(doc, `/html/body/div[2]/section/div/div[1]/div/div[7]`)
-	if err != nil {
-		return nil, err
-	}
-	divNode, err = htmlquery.QueryAll(node, "//div")
-	if err != nil {
-		return nil, err
+	mapped, missing := mapColumns(headers, dseSummaryColumns)
+	if missing != "" {
+		return nil, nil, errLayoutChanged(url, fmt.Sprintf("column %q", missing))
 	}
+	return indices, &table{columns: mapped, rows: [][]*html.Node{values}}, nil
+}
 
-	for index, v := range divNode {
-		switch index {
-		case 1:
-			issuesAdvancedString := htmlquery.InnerText(v)
-			issuesAdvanced, err := strconv.ParseInt(strings.TrimSpace(issuesAdvancedString), 10, 64)
-			if err != nil {
-				return nil, err
-			}
-			dseMarketSummary.IssuesAdvanced = int32(issuesAdvanced)
-		case 2:
-			issuesDeclinedString := htmlquery.InnerText(v)
-			issuesDeclined, err := strconv.ParseInt(strings.TrimSpace(issuesDeclinedString), 10, 64)
-			if err != nil {
-				return nil, err
-			}
-			dseMarketSummary.IssuesDeclined = int32(issuesDeclined)
-		case 3:
-			issuesUnchangedString := htmlquery.InnerText(v)
-			issuesUnchanged, err := strconv.ParseInt(strings.TrimSpace(strings.Replace(issuesUnchangedString, "%", "", -1)), 10, 64)
-			if err != nil {
-				return nil, err
-			}
-			dseMarketSummary.IssuesUnchanged = int32(issuesUnchanged)
-		}
+// dseLastUpdateLayout is the layout of the date and the time of the "Last update on <date> at <time>" text
+const dseLastUpdateLayout = "Jan 2, 2006 3:04 PM"
+
+// splitLastUpdate splits the date and time from the "Last update on <date> at <time>" text of the node
+func splitLastUpdate(node *html.Node, url string) (string, string, error) {
+	if node == nil {
+		return "", "", errLayoutChanged(url, "last update time")
 	}
+	dateTimeText := htmlquery.InnerText(node)
 
-	return dseMarketSummary, nil
+	splitDateTime := strings.Split(dateTimeText, "Last update on ")
+	if len(splitDateTime) != 2 {
+		return "", "", errLayoutChanged(url, "last update time")
+	}
+	dateTime := strings.Split(splitDateTime[1], " at ")
+	if len(dateTime) != 2 {
+		return "", "", errLayoutChanged(url, "last update time")
+	}
+	return strings.TrimSpace(dateTime[0]), strings.TrimSpace(dateTime[1]), nil
 }
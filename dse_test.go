@@ -1,8 +1,17 @@
 package bdstockexchange
 
 import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/antchfx/htmlquery"
 )
 
 func TestNewDSE(t *testing.T) {
@@ -11,7 +20,7 @@ func TestNewDSE(t *testing.T) {
 		want *DSE
 	}{
 		// TODO: Add test cases.
-		{"new", new(DSE)},
+		{"new", &DSE{client: client{baseURL: dseBaseURL, session: newSession()}}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -33,11 +42,13 @@ func Test_getDSELatestPrices(t *testing.T) {
 		wantErr bool
 	}{
 		// TODO: Add test cases.
-		{"", args{""}, make([]*DSEShare, 359), false},
+		{"", args{""}, make([]*DSEShare, 8), false},
+		{"", args{"https://www.dsebd.org/latest_share_price_all_group.php?group=A"}, make([]*DSEShare, 7), false},
+		{"", args{"https://www.dsebd.org/not_recorded.php"}, nil, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := getDSELatestPrices(tt.args.url)
+			got, err := testDSE().getLatestPrices(context.Background(), tt.args.url)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("getDSELatestPrices() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -63,11 +74,13 @@ func TestDSE_GetLatestPricesByCategory(t *testing.T) {
 		wantErr bool
 	}{
 		// TODO: Add test cases.
-		{"", NewDSE(), args{"A", SortByHighPrice, ASC}, make([]*DSEShare, 258), false},
+		{"", testDSE(), args{"A", SortByHighPrice, ASC}, make([]*DSEShare, 7), false},
+		{"", testDSE(), args{"a", SortByTradingCode, DESC}, make([]*DSEShare, 7), false},
+		{"", testDSE(), args{"X", SortByTradingCode, ASC}, nil, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			d := &DSE{}
+			d := tt.d
 			got, err := d.GetLatestPricesByCategory(tt.args.categoryName, tt.args.by, tt.args.order)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("DSE.GetLatestPricesByCategory() error = %v, wantErr %v", err, tt.wantErr)
@@ -93,11 +106,12 @@ func TestDSE_GetLatestPrices(t *testing.T) {
 		wantErr bool
 	}{
 		// TODO: Add test cases.
-		{"", NewDSE(), args{SortByHighPrice, ASC}, make([]*DSEShare, 359), false},
+		{"", testDSE(), args{SortByHighPrice, ASC}, make([]*DSEShare, 8), false},
+		{"", testDSE(), args{SortByHighPrice, 5}, nil, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			d := &DSE{}
+			d := tt.d
 			got, err := d.GetLatestPrices(tt.args.by, tt.args.order)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("DSE.GetLatestPrices() error = %v, wantErr %v", err, tt.wantErr)
@@ -141,7 +155,8 @@ func Test_sortDse(t *testing.T) {
 		{"", args{make([]*DSEShare, 0), SortByValue, DESC}, make([]*DSEShare, 0), false},
 		{"", args{make([]*DSEShare, 0), SortByVolumeOfShare, ASC}, make([]*DSEShare, 0), false},
 		{"", args{make([]*DSEShare, 0), SortByVolumeOfShare, DESC}, make([]*DSEShare, 0), false},
-		{"", args{make([]*DSEShare, 0), SortByPercentageChange, DESC}, nil, true},
+		{"", args{make([]*DSEShare, 0), SortByPercentageChange, DESC}, make([]*DSEShare, 0), false},
+		{"", args{make([]*DSEShare, 0), SortByOpeningPrice, DESC}, nil, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -156,3 +171,109 @@ func Test_sortDse(t *testing.T) {
 		})
 	}
 }
+
+func TestDSE_GetLatestPricesContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	d := NewDSE()
+	_, err := d.GetLatestPricesContext(ctx, SortByLTP, ASC)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("DSE.GetLatestPricesContext() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestDSE_GetLatestPrices_golden(t *testing.T) {
+	got, err := testDSE().GetLatestPrices(SortByTradingCode, ASC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "dse_latest_prices", got)
+}
+
+func TestDSE_GetLatestPricesSortedByPercentageChange(t *testing.T) {
+	got, err := testDSE().GetLatestPricesSortedByPercentageChange()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "dse_latest_prices_by_percentage_change", got)
+}
+
+func TestDSE_GetMarketStatus(t *testing.T) {
+	got, err := testDSE().GetMarketStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "dse_market_status", got)
+}
+
+func TestDSE_GetMarketStatus_moved(t *testing.T) {
+	page, err := ioutil.ReadFile(filepath.Join("testdata", "www.dsebd.org", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the last update moved into a notice box
+	page = []byte(strings.Replace(string(page), `<h2 class="BodyHead topBodyHead">Last update on Oct 15, 2020 at 3:10 PM</h2>`,
+		`<div class="notice"><h2 class="BodyHead">Last update on Oct 15, 2020 at 3:10 PM</h2></div>`, 1))
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(page)
+	}))
+	defer ts.Close()
+
+	got, err := NewDSE(WithBaseURL(ts.URL)).GetMarketStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.LastUpdatedOn.Date != "Oct 15, 2020" || got.LastUpdatedOn.Time != "3:10 PM" {
+		t.Errorf("GetMarketStatus() last update = %+v, want Oct 15, 2020 at 3:10 PM", got.LastUpdatedOn)
+	}
+}
+
+func TestDSE_GetMarketSummary(t *testing.T) {
+	got, err := testDSE().GetMarketSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "dse_market_summary", got)
+}
+
+func Test_readSummaryRows(t *testing.T) {
+	totals := `<div class="midrow"><div>Total Trade</div><div>Total Volume</div><div>Total Value in Taka (mn)</div></div>
+<div class="midrow"><div>98,765</div><div>180,123,456</div><div>5,432.105</div></div>
+<div class="midrow"><div>Issues Advanced</div><div>Issues declined</div><div>Issues Unchanged</div></div>
+<div class="midrow"><div>152</div><div>121</div><div>78</div></div>`
+	marketCap := `<div class="midrow"><div>Total Market Capitalization in Taka (mn)</div><div>Equity Market Capitalization in Taka (mn)</div></div>
+<div class="midrow"><div>4,012,345.678</div><div>3,187,654.321</div></div>`
+	tests := []struct {
+		name    string
+		page    string
+		wantErr error
+	}{
+		{"all rows", `<div class="midrow"><div>DS30 Index</div><div>1,701.92</div><div>-5.80</div><div>-0.34%</div></div>` + totals + marketCap, nil},
+		{"no market capitalization", totals, ErrLayoutChanged},
+		{"no rows", `<div class="row"></div>`, ErrLayoutChanged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := htmlquery.Parse(strings.NewReader(tt.page))
+			if err != nil {
+				t.Fatal(err)
+			}
+			indices, totals, err := readSummaryRows(doc, "https://www.dsebd.org/")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("readSummaryRows() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := indices["DS30"]; !reflect.DeepEqual(got, []string{"1,701.92", "-5.80", "-0.34%"}) {
+				t.Errorf("readSummaryRows() DS30 = %q", got)
+			}
+			for column, want := range map[string]string{"total_value": "5,432.105", "issues_declined": "121", "equity_market_cap": "3,187,654.321"} {
+				if got := totals.text(totals.rows[0], column); got != want {
+					t.Errorf("readSummaryRows() %s = %q, want %q", column, got, want)
+				}
+			}
+		})
+	}
+}
@@ -1,11 +1,103 @@
 package bdstockexchange
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrNetwork is returned when the exchange website can not be reached. The returned error is a *RequestError
+	ErrNetwork = errors.New("failed to fetch data. server offline")
+	// ErrHTTPStatus is returned when the exchange website responds with a non 2xx status. The returned error is a *HTTPStatusError
+	ErrHTTPStatus = errors.New("unexpected http status")
+	// ErrRateLimited is returned when the exchange website responds with 429 Too Many Requests
+	ErrRateLimited = errors.New("rate limited by the server")
+	// ErrLayoutChanged is returned when a page does not have the structure the parser expects
+	ErrLayoutChanged = errors.New("page layout changed")
+	// ErrNoDataFound is returned when the exchange has no data for the input, ex: a PE ratio date the market was closed
+	ErrNoDataFound = errors.New("no data found")
+	// ErrInvalidArgument is returned when a method is called with an illegal argument
+	ErrInvalidArgument = errors.New("invalid argument")
+)
 
 var (
-	// errInvalidGroupName is thrown when user input an illegal group name
-	errInvalidGroupName = errors.New("group name is invalid. enter a valid group name. ex : A, B, G, N, Z")
-	errErrorFetchingUrl = errors.New("failed to fetch data. server offline")
-	errNoDataFound      = errors.New("no data found")
-	errNotAValidYear    = errors.New("data not available for this Year")
+	// ErrInvalidGroupName is returned when user input an illegal group name. It wraps ErrInvalidArgument
+	ErrInvalidGroupName = fmt.Errorf("%w: group name is invalid. enter a valid group name. ex : A, B, G, N, Z", ErrInvalidArgument)
+	// ErrInvalidSortOrder is returned when the sort order is not ASC or DESC. It wraps ErrInvalidArgument
+	ErrInvalidSortOrder = fmt.Errorf("%w: order param is not valid. put a ASC or DESC as order param", ErrInvalidArgument)
+	// ErrInvalidSortBy is returned when the result can not be sorted by the sort by param. It wraps ErrInvalidArgument
+	ErrInvalidSortBy = fmt.Errorf("%w: sorting with the given sort by param is not possible. try another one", ErrInvalidArgument)
+	// ErrNotAValidYear is returned when the exchange has no data for the input year. It wraps ErrInvalidArgument
+	ErrNotAValidYear = fmt.Errorf("%w: data not available for this Year", ErrInvalidArgument)
 )
+
+// RequestError is returned when a request to the exchange website fails before a response is received or the body of
+// the response can not be read, ex: the connection is reset
+type RequestError struct {
+	URL string
+	Err error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrNetwork, e.Err)
+}
+
+// Unwrap returns the underlying transport error
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is ErrNetwork
+func (e *RequestError) Is(target error) bool {
+	return target == ErrNetwork
+}
+
+// HTTPStatusError is returned when the exchange website responds with a non 2xx status
+type HTTPStatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("%v %q from %s", ErrHTTPStatus, e.Status, e.URL)
+}
+
+// Is reports whether the target is ErrHTTPStatus, or ErrRateLimited for a 429 status
+func (e *HTTPStatusError) Is(target error) bool {
+	return target == ErrHTTPStatus || (target == ErrRateLimited && e.StatusCode == http.StatusTooManyRequests)
+}
+
+// ParseError is returned when a value of an exchange page can not be parsed. It matches ErrLayoutChanged with errors.Is
+type ParseError struct {
+	// URL is the page the value was read from
+	URL string
+	// Row is the index of the row or section of the page the value belongs to
+	Row int
+	// Column is the name of the field the value was parsed for
+	Column string
+	// Text is the raw text of the value
+	Text string
+	// Err is the underlying parse error
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("failed to parse %s %q at row %d of %s: %v", e.Column, e.Text, e.Row, e.URL, e.Err)
+}
+
+// Unwrap returns the underlying parse error
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is ErrLayoutChanged
+func (e *ParseError) Is(target error) bool {
+	return target == ErrLayoutChanged
+}
+
+// errLayoutChanged returns an error wrapping ErrLayoutChanged for a missing part of a page
+func errLayoutChanged(url, what string) error {
+	return fmt.Errorf("%w: %s not found on %s", ErrLayoutChanged, what, url)
+}
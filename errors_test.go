@@ -0,0 +1,53 @@
+package bdstockexchange
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestErrors_Is(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		{"invalid group name", ErrInvalidGroupName, ErrInvalidArgument, true},
+		{"invalid sort order", ErrInvalidSortOrder, ErrInvalidArgument, true},
+		{"not a valid year", ErrNotAValidYear, ErrNoDataFound, false},
+		{"request", &RequestError{URL: "https://www.dsebd.org/", Err: errors.New("timeout")}, ErrNetwork, true},
+		{"status", &HTTPStatusError{StatusCode: http.StatusInternalServerError}, ErrHTTPStatus, true},
+		{"status not rate limited", &HTTPStatusError{StatusCode: http.StatusInternalServerError}, ErrRateLimited, false},
+		{"rate limited", &HTTPStatusError{StatusCode: http.StatusTooManyRequests}, ErrRateLimited, true},
+		{"parse", &ParseError{Err: strconv.ErrSyntax}, ErrLayoutChanged, true},
+		{"parse unwrap", &ParseError{Err: strconv.ErrSyntax}, strconv.ErrSyntax, true},
+		{"layout changed", errLayoutChanged("https://www.dsebd.org/", "market status"), ErrLayoutChanged, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errors.Is(tt.err, tt.target); got != tt.want {
+				t.Errorf("errors.Is(%v, %v) = %v, want %v", tt.err, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_client_loadURL_status(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	d := NewDSE(WithBaseURL(ts.URL))
+	_, err := d.GetMarketStatus()
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("DSE.GetMarketStatus() error = %v, want %v", err, ErrRateLimited)
+	}
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("DSE.GetMarketStatus() error = %v, want a *HTTPStatusError with status %d", err, http.StatusTooManyRequests)
+	}
+}
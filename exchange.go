@@ -0,0 +1,223 @@
+package bdstockexchange
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// ExchangeDSE is the name of the Dhaka Stock Exchange
+	ExchangeDSE = "DSE"
+	// ExchangeCSE is the name of the Chittagong Stock Exchange
+	ExchangeCSE = "CSE"
+)
+
+// Exchange is implemented by DSE and CSE, so the code using it can be written once and run against either exchange
+type Exchange interface {
+	// Name returns the name of the exchange, ExchangeDSE or ExchangeCSE
+	Name() string
+	// Quotes returns the latest price of every share traded on the exchange
+	Quotes(ctx context.Context) ([]*Quote, error)
+	// Status returns if the market is open
+	Status(ctx context.Context) (*MarketStatus, error)
+	// Snapshot returns the indices and the totals of the last trading day
+	Snapshot(ctx context.Context) (*MarketSnapshot, error)
+}
+
+var (
+	_ Exchange = (*DSE)(nil)
+	_ Exchange = (*CSE)(nil)
+)
+
+// Quote is the latest price of a share on an exchange. DSE or CSE holds the exchange specific model it is made of.
+// The dse does not publish the opening price and the cse does not publish the closing price, so Open is 0 for the
+// dse and ClosePrice is 0 for the cse
+type Quote struct {
+	Exchange         string  `json:"exchange"`
+	TradingCode      string  `json:"trading_code"`
+	LTP              Decimal `json:"ltp"`
+	Open             Decimal `json:"open"`
+	High             Decimal `json:"high"`
+	Low              Decimal `json:"low"`
+	ClosePrice       Decimal `json:"close_price"`
+	YCP              Decimal `json:"ycp"`
+	Change           Decimal `json:"change"`
+	PercentageChange float64 `json:"percentage_change"`
+	Trade            int64   `json:"trade"`
+	ValueInMN        Decimal `json:"value"`
+	Volume           int64   `json:"volume"`
+	// Time is the last update of the dse or the trading day of the cse in the Dhaka location
+	Time time.Time `json:"time"`
+
+	DSE *DSEShare `json:"-"`
+	CSE *CSEShare `json:"-"`
+}
+
+// MarketStatus is the status of the market of an exchange. DSE or CSE holds the exchange specific model it is made of
+type MarketStatus struct {
+	Exchange string `json:"exchange"`
+	IsOpen   bool   `json:"is_open"`
+
+	DSE *DseMarketStatus `json:"-"`
+	CSE *CseMarketStatus `json:"-"`
+}
+
+// Index is the value of a market index and its change from the last trading day
+type Index struct {
+	Name             string  `json:"name"`
+	Value            float64 `json:"value"`
+	Change           float64 `json:"change"`
+	PercentageChange float64 `json:"percentage_change"`
+}
+
+// MarketSnapshot holds the indices and the totals of a trading day of an exchange.
+// DSE or CSE holds the exchange specific model it is made of
+type MarketSnapshot struct {
+	Exchange string `json:"exchange"`
+	// Time is the last update of the dse or the trading day of the cse in the Dhaka location and RawDate is the day
+	// as the exchange prints it, ex: "Oct 15, 2020" on the dse and "15 Oct 2020" on the cse
+	Time           time.Time `json:"time"`
+	RawDate        string    `json:"raw_date"`
+	Indices        []*Index  `json:"indices"`
+	TotalTrade     int64     `json:"total_trade"`
+	TotalVolume    int64     `json:"total_volume"`
+	TotalValueInMN Decimal   `json:"total_value"`
+
+	DSE *MarketSummary `json:"-"`
+	CSE *IndexSnapshot `json:"-"`
+}
+
+// Name returns ExchangeDSE
+func (d *DSE) Name() string {
+	return ExchangeDSE
+}
+
+// Quotes returns the latest price of every share traded on the dse sorted by trading code with the last update of the
+// market status. The dse does not publish the opening price, so Open is always 0
+func (d *DSE) Quotes(ctx context.Context) ([]*Quote, error) {
+	shares, err := d.GetLatestPricesContext(ctx, SortByTradingCode, ASC)
+	if err != nil {
+		return nil, err
+	}
+	status, err := d.GetMarketStatusContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	quotes := make([]*Quote, 0, len(shares))
+	for _, s := range shares {
+		quotes = append(quotes, &Quote{
+			Exchange:         ExchangeDSE,
+			TradingCode:      s.TradingCode,
+			LTP:              s.LTP,
+			High:             s.High,
+			Low:              s.Low,
+			ClosePrice:       s.CloseP,
+			YCP:              s.YCP,
+			Change:           s.Change,
+			PercentageChange: percentageChange(s.LTP.Float64(), s.YCP.Float64()),
+			Trade:            s.Trade,
+			ValueInMN:        s.ValueInMN,
+			Volume:           s.Volume,
+			Time:             status.LastUpdatedAt,
+			DSE:              s,
+		})
+	}
+	return quotes, nil
+}
+
+// Status returns if the dse market is open
+func (d *DSE) Status(ctx context.Context) (*MarketStatus, error) {
+	status, err := d.GetMarketStatusContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &MarketStatus{Exchange: ExchangeDSE, IsOpen: status.IsOpen, DSE: status}, nil
+}
+
+// Snapshot returns the DSEX, DSES and DS30 indices and the totals of the dse home page
+func (d *DSE) Snapshot(ctx context.Context) (*MarketSnapshot, error) {
+	summary, err := d.GetMarketSummaryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &MarketSnapshot{
+		Exchange: ExchangeDSE,
+		Time:     summary.LastUpdatedAt,
+		RawDate:  summary.LastUpdatedOn.Date,
+		Indices: []*Index{
+			{"DSEX", summary.DseX.DSEXIndex, summary.DseX.DSEXIndexChange, summary.DseX.DSEXIndexChangePercentage},
+			{"DSES", summary.DseS.DSESIndex, summary.DseS.DSESIndexChange, summary.DseS.DSESIndexChangePercentage},
+			{"DS30", summary.Ds30.DS30Index, summary.Ds30.DS30IndexChange, summary.Ds30.DS30IndexChangePercentage},
+		},
+		TotalTrade:     summary.TotalTrade,
+		TotalVolume:    summary.TotalVolume,
+		TotalValueInMN: summary.TotalValueInMN,
+		DSE:            summary,
+	}, nil
+}
+
+// Name returns ExchangeCSE
+func (c *CSE) Name() string {
+	return ExchangeCSE
+}
+
+// Quotes returns the latest price of every share traded on the cse sorted by trading code with the day of the home
+// page. The cse does not publish the closing price, so ClosePrice is always 0
+func (c *CSE) Quotes(ctx context.Context) ([]*Quote, error) {
+	shares, err := c.GetLatestPricesContext(ctx, SortByTradingCode, ASC)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := c.GetIndexSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	quotes := make([]*Quote, 0, len(shares))
+	for _, s := range shares {
+		quotes = append(quotes, &Quote{
+			Exchange:         ExchangeCSE,
+			TradingCode:      s.TradingCode,
+			LTP:              s.LTP,
+			Open:             s.Open,
+			High:             s.High,
+			Low:              s.Low,
+			YCP:              s.YCP,
+			Change:           s.Change(),
+			PercentageChange: s.PercentageChange(),
+			Trade:            s.Trade,
+			ValueInMN:        s.ValueInMN,
+			Volume:           s.Volume,
+			Time:             snapshot.Time,
+			CSE:              s,
+		})
+	}
+	return quotes, nil
+}
+
+// Status returns if the cse market is open
+func (c *CSE) Status(ctx context.Context) (*MarketStatus, error) {
+	status, err := c.GetMarketStatusContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &MarketStatus{Exchange: ExchangeCSE, IsOpen: status.IsOpen, CSE: status}, nil
+}
+
+// Snapshot returns the CASPI, CSE30, CSCX, CSE50 and CSI indices and the totals of the day from the cse home page,
+// the same as GetIndexSnapshot
+func (c *CSE) Snapshot(ctx context.Context) (*MarketSnapshot, error) {
+	snapshot, err := c.GetIndexSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &MarketSnapshot{
+		Exchange:       ExchangeCSE,
+		Time:           snapshot.Time,
+		RawDate:        snapshot.Date,
+		Indices:        snapshot.Indices,
+		TotalTrade:     snapshot.TotalTrade,
+		TotalVolume:    snapshot.TotalVolume,
+		TotalValueInMN: snapshot.TotalValueInMN,
+		CSE:            snapshot,
+	}, nil
+}
@@ -0,0 +1,73 @@
+package bdstockexchange
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestExchange(t *testing.T) {
+	tests := []struct {
+		exchange   Exchange
+		name       string
+		quotes     int
+		firstIndex string
+	}{
+		{testDSE(), ExchangeDSE, 8, "DSEX"},
+		{testCSE(), ExchangeCSE, 6, "CASPI"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if got := tt.exchange.Name(); got != tt.name {
+				t.Errorf("Exchange.Name() = %v, want %v", got, tt.name)
+			}
+
+			quotes, err := tt.exchange.Quotes(ctx)
+			if err != nil {
+				t.Fatalf("Exchange.Quotes() error = %v", err)
+			}
+			if len(quotes) != tt.quotes {
+				t.Errorf("Exchange.Quotes() = %d quotes, want %d", len(quotes), tt.quotes)
+			}
+			for _, q := range quotes {
+				if q.Exchange != tt.name || q.TradingCode == "" || q.LTP.Sign() <= 0 || (q.DSE == nil) == (q.CSE == nil) ||
+					q.Time.Location() != Dhaka || q.Time.Format("2006-01-02") != "2020-10-15" {
+					t.Errorf("Exchange.Quotes() quote = %+v", q)
+				}
+			}
+
+			status, err := tt.exchange.Status(ctx)
+			if err != nil {
+				t.Fatalf("Exchange.Status() error = %v", err)
+			}
+			if status.Exchange != tt.name || (status.DSE == nil) == (status.CSE == nil) {
+				t.Errorf("Exchange.Status() = %+v", status)
+			}
+
+			snapshot, err := tt.exchange.Snapshot(ctx)
+			if err != nil {
+				t.Fatalf("Exchange.Snapshot() error = %v", err)
+			}
+			if snapshot.Indices[0].Name != tt.firstIndex {
+				t.Errorf("Exchange.Snapshot() first index = %v, want %v", snapshot.Indices[0].Name, tt.firstIndex)
+			}
+			if snapshot.Time.Location() != Dhaka || snapshot.Time.Format("2006-01-02") != "2020-10-15" {
+				t.Errorf("Exchange.Snapshot() time = %v, want 2020-10-15 in Dhaka", snapshot.Time)
+			}
+			assertGolden(t, strings.ToLower(tt.name)+"_snapshot", snapshot)
+		})
+	}
+}
+
+func TestCSE_Quotes(t *testing.T) {
+	quotes, err := testCSE().Quotes(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gp := quotes[4]
+	if gp.TradingCode != "GP" || !gp.Open.Equal(MustParseDecimal("321")) || !gp.CSE.Open.Equal(gp.Open) || !gp.Change.Equal(gp.LTP.Sub(gp.YCP)) ||
+		!gp.ClosePrice.IsZero() {
+		t.Errorf("CSE.Quotes() GP = %+v", gp)
+	}
+}
@@ -6,5 +6,6 @@ require (
 	github.com/PuerkitoBio/goquery v1.5.1
 	github.com/andybalholm/cascadia v1.2.0 // indirect
 	github.com/antchfx/htmlquery v1.2.3
+	github.com/ledongthuc/pdf v0.0.0-20220302134840-0c2507a12d80
 	golang.org/x/net v0.0.0-20200707034311-ab3426394381
 )
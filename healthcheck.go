@@ -0,0 +1,535 @@
+package bdstockexchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// HealthReport holds the result of a Healthcheck for every page of an exchange website
+type HealthReport struct {
+	Exchange  string            `json:"exchange"`
+	CheckedAt time.Time         `json:"checked_at"`
+	Endpoints []*EndpointHealth `json:"endpoints"`
+}
+
+// Healthy returns true if every endpoint of the report is healthy
+func (r *HealthReport) Healthy() bool {
+	for _, e := range r.Endpoints {
+		if !e.Healthy {
+			return false
+		}
+	}
+	return true
+}
+
+// EndpointHealth holds the result of fetching, parsing and validating one page of an exchange website
+type EndpointHealth struct {
+	Name     string        `json:"name"`
+	URL      string        `json:"url"`
+	Healthy  bool          `json:"healthy"`
+	Rows     int           `json:"rows"`
+	Duration time.Duration `json:"duration"`
+	Err      error         `json:"-"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// healthCheck fetches and validates one page. run returns the number of rows parsed
+type healthCheck struct {
+	name string
+	url  string
+	run  func(ctx context.Context) (int, error)
+}
+
+// runHealthChecks runs the checks one after another and returns the report
+func runHealthChecks(ctx context.Context, exchange string, checks []healthCheck) *HealthReport {
+	report := &HealthReport{
+		Exchange:  exchange,
+		CheckedAt: time.Now(),
+		Endpoints: make([]*EndpointHealth, 0, len(checks)),
+	}
+	for _, c := range checks {
+		start := time.Now()
+		rows, err := runHealthCheck(ctx, c)
+		e := &EndpointHealth{
+			Name:     c.name,
+			URL:      c.url,
+			Healthy:  err == nil,
+			Rows:     rows,
+			Duration: time.Since(start),
+			Err:      err,
+		}
+		if err != nil {
+			e.Error = err.Error()
+		}
+		report.Endpoints = append(report.Endpoints, e)
+	}
+	return report
+}
+
+// runHealthCheck runs the check. A panic of the parser on a changed page is returned as an error wrapping
+// ErrLayoutChanged, so the check is reported as unhealthy instead of crashing the healthcheck
+func runHealthCheck(ctx context.Context, c healthCheck) (rows int, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			rows, err = 0, fmt.Errorf("%w: parsing %s panicked: %v", ErrLayoutChanged, c.url, v)
+		}
+	}()
+	return c.run(ctx)
+}
+
+// errImplausible returns an error wrapping ErrLayoutChanged for a value out of its expected range
+func errImplausible(url, format string, a ...interface{}) error {
+	return fmt.Errorf("%w: implausible data on %s: %s", ErrLayoutChanged, url, fmt.Sprintf(format, a...))
+}
+
+// priceRow holds the values of a latest price row which are validated by a healthcheck
+type priceRow struct {
+	code           string
+	ltp, high, low float64
+	volume         int64
+}
+
+// validatePrices checks that a price page has rows, the trading codes are set and unique,
+// the prices are not negative and the high price is not below the low price
+func validatePrices(url string, rows []priceRow) error {
+	codes := make([]string, 0, len(rows))
+	for _, r := range rows {
+		if r.ltp < 0 || r.high < 0 || r.low < 0 || r.volume < 0 {
+			return errImplausible(url, "negative value for %s", r.code)
+		}
+		if r.high < r.low {
+			return errImplausible(url, "high price %v below low price %v for %s", r.high, r.low, r.code)
+		}
+		codes = append(codes, r.code)
+	}
+	return validateRows(url, codes)
+}
+
+// validateRows checks that a page has rows and that the trading codes are set and unique
+func validateRows(url string, codes []string) error {
+	if len(codes) == 0 {
+		return errImplausible(url, "no rows")
+	}
+	seen := make(map[string]bool, len(codes))
+	for i, code := range codes {
+		if code == "" {
+			return errImplausible(url, "empty trading code at row %d", i)
+		}
+		if seen[code] {
+			return errImplausible(url, "duplicate trading code %s", code)
+		}
+		seen[code] = true
+	}
+	return nil
+}
+
+// validateIndex checks that an index value is positive
+func validateIndex(url, name string, value float64) error {
+	if value <= 0 {
+		return errImplausible(url, "%s index is %v", name, value)
+	}
+	return nil
+}
+
+// healthcheckTradingCode is the share whose pages are checked. It is listed on both exchanges
+const healthcheckTradingCode = "GP"
+
+// lookBack returns a check of the price earning ratio report of the last day with data in the week up to the day,
+// as the market is closed on weekends and holidays
+func lookBack(today time.Time, url string, get func(ctx context.Context, day time.Time) (*PriceEarningRatios, error)) func(ctx context.Context) (int, error) {
+	return func(ctx context.Context) (int, error) {
+		day := today
+		for i := 0; i < 7; i++ {
+			pe, err := get(ctx, day)
+			if errors.Is(err, ErrNoDataFound) {
+				day = day.AddDate(0, 0, -1)
+				continue
+			}
+			if err != nil {
+				return 0, err
+			}
+			codes := make([]string, 0, len(pe.PriceEarningRatioArray))
+			for _, r := range pe.PriceEarningRatioArray {
+				codes = append(codes, r.TradingCode)
+			}
+			return len(codes), validateRows(url, codes)
+		}
+		return 0, errImplausible(url, "no price earning ratio for the last 7 days")
+	}
+}
+
+// Healthcheck fetches every supported dse page, validates the required nodes and the range of the values and
+// returns a report per endpoint. A page must have at least one row and its trading codes must be set and unique, the
+// number of rows is reported but not bounded as it changes with the trading day. It is meant to be run from
+// monitoring to catch a changed website layout
+func (d *DSE) Healthcheck(ctx context.Context) *HealthReport {
+	latestPrices := func(url string, get func(ctx context.Context) ([]*DSEShare, error)) func(ctx context.Context) (int, error) {
+		return func(ctx context.Context) (int, error) {
+			arr, err := get(ctx)
+			if err != nil {
+				return 0, err
+			}
+			rows := make([]priceRow, 0, len(arr))
+			for _, s := range arr {
+				rows = append(rows, priceRow{s.TradingCode, s.LTP.Float64(), s.High.Float64(), s.Low.Float64(), s.Volume})
+			}
+			return len(arr), validatePrices(url, rows)
+		}
+	}
+
+	topMovers := func(kind moverKind, n int) func(ctx context.Context) (int, error) {
+		return func(ctx context.Context) (int, error) {
+			arr, err := d.GetTopMovers(ctx, kind, n)
+			if err != nil {
+				return 0, err
+			}
+			// a day without a rise or without a fall has no gainers or losers
+			if len(arr) == 0 && (kind == TopGainers || kind == TopLosers) {
+				return 0, nil
+			}
+			rows := make([]priceRow, 0, len(arr))
+			for _, s := range arr {
+				rows = append(rows, priceRow{s.TradingCode, s.LTP.Float64(), s.High.Float64(), s.Low.Float64(), s.Volume})
+			}
+			return len(arr), validatePrices(d.url("/"), rows)
+		}
+	}
+	companies := func(get func(ctx context.Context) ([]*Company, error)) func(ctx context.Context) (int, error) {
+		return func(ctx context.Context) (int, error) {
+			list, err := get(ctx)
+			if err != nil {
+				return 0, err
+			}
+			codes := make([]string, 0, len(list))
+			for _, company := range list {
+				codes = append(codes, company.TradingCode)
+			}
+			return len(list), validateRows(d.url("/company_listing.php"), codes)
+		}
+	}
+
+	checks := []healthCheck{
+		{
+			name: "latest_prices",
+			url:  d.url("/latest_share_price_scroll_l.php"),
+			run: latestPrices(d.url("/latest_share_price_scroll_l.php"), func(ctx context.Context) ([]*DSEShare, error) {
+				return d.GetLatestPricesContext(ctx, SortByTradingCode, ASC)
+			}),
+		},
+		{
+			name: "latest_prices_by_category",
+			url:  d.url("/latest_share_price_all_group.php?group=A"),
+			run: latestPrices(d.url("/latest_share_price_all_group.php?group=A"), func(ctx context.Context) ([]*DSEShare, error) {
+				return d.GetLatestPricesByCategoryContext(ctx, "A", SortByTradingCode, ASC)
+			}),
+		},
+		{
+			name: "latest_prices_by_percentage_change",
+			url:  d.url("/latest_share_price_all_by_change.php"),
+			run: func(ctx context.Context) (int, error) {
+				url := d.url("/latest_share_price_all_by_change.php")
+				arr, err := d.GetLatestPricesSortedByPercentageChangeContext(ctx)
+				if err != nil {
+					return 0, err
+				}
+				rows := make([]priceRow, 0, len(arr))
+				for _, s := range arr {
+					rows = append(rows, priceRow{s.TradingCode, s.LTP.Float64(), s.High.Float64(), s.Low.Float64(), s.Volume})
+				}
+				return len(arr), validatePrices(url, rows)
+			},
+		},
+		{
+			name: "market_status",
+			url:  d.url("/"),
+			run: func(ctx context.Context) (int, error) {
+				status, err := d.GetMarketStatusContext(ctx)
+				if err != nil {
+					return 0, err
+				}
+				if status.LastUpdatedOn.Date == "" || status.LastUpdatedOn.Time == "" {
+					return 1, errImplausible(d.url("/"), "empty last update time")
+				}
+				return 1, nil
+			},
+		},
+		{
+			name: "market_summary",
+			url:  d.url("/"),
+			run: func(ctx context.Context) (int, error) {
+				summary, err := d.GetMarketSummaryContext(ctx)
+				if err != nil {
+					return 0, err
+				}
+				url := d.url("/")
+				if err := validateIndex(url, "DSEX", summary.DseX.DSEXIndex); err != nil {
+					return 1, err
+				}
+				if err := validateIndex(url, "DSES", summary.DseS.DSESIndex); err != nil {
+					return 1, err
+				}
+				if err := validateIndex(url, "DS30", summary.Ds30.DS30Index); err != nil {
+					return 1, err
+				}
+				if summary.TotalTrade < 0 || summary.TotalVolume < 0 {
+					return 1, errImplausible(url, "negative total trade or volume")
+				}
+				return 1, nil
+			},
+		},
+		{name: "top_gainers", url: d.url("/"), run: topMovers(TopGainers, 10)},
+		{name: "top_losers", url: d.url("/"), run: topMovers(TopLosers, 10)},
+		{name: "top_by_value", url: d.url("/"), run: topMovers(TopByValue, 20)},
+		{name: "top_by_volume", url: d.url("/"), run: topMovers(TopByVolume, 20)},
+		{name: "top_by_trade", url: d.url("/"), run: topMovers(TopByTrade, 20)},
+		{
+			name: "historical_prices",
+			url:  d.url("/day_end_archive.php"),
+			run: func(ctx context.Context) (int, error) {
+				// two weeks have a trading day even with the longest holidays
+				to := d.client.now()
+				prices, err := d.GetHistoricalPrices(ctx, healthcheckTradingCode, to.AddDate(0, 0, -13), to)
+				if err != nil {
+					return 0, err
+				}
+				for _, p := range prices {
+					if p.Close.Sign() <= 0 || p.High.Cmp(p.Low) < 0 {
+						return len(prices), errImplausible(d.url("/day_end_archive.php"), "close price %v, high price %v and low price %v on %s",
+							p.Close, p.High, p.Low, p.Date.Format("2006-01-02"))
+					}
+				}
+				return len(prices), nil
+			},
+		},
+		{
+			name: "company",
+			url:  d.url("/displayCompany.php?name=" + healthcheckTradingCode),
+			run: func(ctx context.Context) (int, error) {
+				profile, err := d.GetCompany(ctx, healthcheckTradingCode)
+				if err != nil {
+					return 0, err
+				}
+				if profile.CompanyName == "" || profile.OutstandingSecurities <= 0 {
+					return 1, errImplausible(d.url("/displayCompany.php?name="+healthcheckTradingCode), "empty company name or no securities")
+				}
+				return 1, nil
+			},
+		},
+		{
+			name: "listed_companies",
+			url:  d.url("/company_listing.php"),
+			run:  companies(d.GetAllListedCompaniesContext),
+		},
+		{
+			name: "listed_companies_by_industry",
+			url:  d.url("/by_industrylisting.php"),
+			run: companies(func(ctx context.Context) ([]*Company, error) {
+				listing, err := d.GetAllListedCompaniesByIndustryContext(ctx)
+				if err != nil {
+					return nil, err
+				}
+				list := make([]*Company, 0)
+				for _, l := range listing {
+					list = append(list, l.List...)
+				}
+				return list, nil
+			}),
+		},
+		{
+			name: "listed_companies_by_category",
+			url:  d.url("/company_listing.php"),
+			run: companies(func(ctx context.Context) ([]*Company, error) {
+				listing, err := d.GetAllListedCompaniesByCategoryContext(ctx)
+				if err != nil {
+					return nil, err
+				}
+				list := make([]*Company, 0)
+				for _, l := range listing {
+					list = append(list, l.List...)
+				}
+				return list, nil
+			}),
+		},
+		{
+			name: "price_earning_ratio",
+			url:  d.url("/pe_archive.php"),
+			run: lookBack(d.client.now(), d.url("/pe_archive.php"), func(ctx context.Context, day time.Time) (*PriceEarningRatios, error) {
+				return d.GetPriceEarningRatio(ctx, day)
+			}),
+		},
+	}
+	return runHealthChecks(ctx, "DSE", checks)
+}
+
+// Healthcheck fetches every supported cse page, validates the required nodes and the range of the values and
+// returns a report per endpoint. A page must have at least one row and its trading codes must be set and unique, the
+// number of rows is reported but not bounded as it changes with the trading day. It is meant to be run from
+// monitoring to catch a changed website layout
+func (c *CSE) Healthcheck(ctx context.Context) *HealthReport {
+	companies := func(get func(ctx context.Context) ([]*Company, error)) func(ctx context.Context) (int, error) {
+		return func(ctx context.Context) (int, error) {
+			list, err := get(ctx)
+			if err != nil {
+				return 0, err
+			}
+			codes := make([]string, 0, len(list))
+			for _, company := range list {
+				codes = append(codes, company.TradingCode)
+			}
+			return len(list), validateRows(c.url("/company/listedcompanies"), codes)
+		}
+	}
+
+	checks := []healthCheck{
+		{
+			name: "latest_prices",
+			url:  c.url("/market/current_price"),
+			run: func(ctx context.Context) (int, error) {
+				url := c.url("/market/current_price")
+				arr, err := c.GetLatestPricesContext(ctx, SortByTradingCode, ASC)
+				if err != nil {
+					return 0, err
+				}
+				rows := make([]priceRow, 0, len(arr))
+				for _, s := range arr {
+					rows = append(rows, priceRow{s.TradingCode, s.LTP.Float64(), s.High.Float64(), s.Low.Float64(), s.Volume})
+				}
+				return len(arr), validatePrices(url, rows)
+			},
+		},
+		{
+			name: "market_status",
+			url:  c.url("/market/current_price"),
+			run: func(ctx context.Context) (int, error) {
+				if _, err := c.GetMarketStatusContext(ctx); err != nil {
+					return 0, err
+				}
+				return 1, nil
+			},
+		},
+		{
+			name: "market_summary",
+			url:  c.url("/market/historical_market"),
+			run: func(ctx context.Context) (int, error) {
+				url := c.url("/market/historical_market")
+				summary, err := c.GetMarketSummaryContext(ctx)
+				if err != nil {
+					return 0, err
+				}
+				if len(summary.HistoricalSummaries) == 0 {
+					return 0, errImplausible(url, "no historical market summary")
+				}
+				latest := summary.HistoricalSummaries[0]
+				if err := validateIndex(url, "CASPI", latest.CASPI); err != nil {
+					return len(summary.HistoricalSummaries), err
+				}
+				if err := validateIndex(url, "CSE30", latest.CSE30); err != nil {
+					return len(summary.HistoricalSummaries), err
+				}
+				return len(summary.HistoricalSummaries), nil
+			},
+		},
+		{
+			name: "listed_companies",
+			url:  c.url("/company/listedcompanies"),
+			run:  companies(c.GetAllListedCompaniesContext),
+		},
+		{
+			name: "listed_companies_by_industry",
+			url:  c.url("/company/listedcompanies"),
+			run: companies(func(ctx context.Context) ([]*Company, error) {
+				listing, err := c.GetAllListedCompaniesByIndustryContext(ctx)
+				if err != nil {
+					return nil, err
+				}
+				list := make([]*Company, 0)
+				for _, l := range listing {
+					list = append(list, l.List...)
+				}
+				return list, nil
+			}),
+		},
+		{
+			name: "listed_companies_by_category",
+			url:  c.url("/company/listedcompanies"),
+			run: companies(func(ctx context.Context) ([]*Company, error) {
+				listing, err := c.GetAllListedCompaniesByCategoryContext(ctx)
+				if err != nil {
+					return nil, err
+				}
+				list := make([]*Company, 0)
+				for _, l := range listing {
+					list = append(list, l.List...)
+				}
+				return list, nil
+			}),
+		},
+		{
+			name: "weekly_reports",
+			url:  c.url("/market/weekly_report"),
+			run: func(ctx context.Context) (int, error) {
+				// the reports of a year are published from its first week, so early in January, when the year is not
+				// in the select yet or has no report, look at the year before
+				year := c.client.now().Year()
+				reports, err := c.GetAllWeeklyReportsContext(ctx, year)
+				if errors.Is(err, ErrNotAValidYear) || (err == nil && len(reports.Reports) == 0) {
+					reports, err = c.GetAllWeeklyReportsContext(ctx, year-1)
+				}
+				if err != nil {
+					return 0, err
+				}
+				if len(reports.Reports) == 0 {
+					return 0, errImplausible(c.url("/market/weekly_report"), "no weekly reports")
+				}
+				return len(reports.Reports), nil
+			},
+		},
+		{
+			name: "price_earning_ratio",
+			url:  c.url("/market/pe_ratio"),
+			run: lookBack(c.client.now(), c.url("/market/pe_ratio"), func(ctx context.Context, day time.Time) (*PriceEarningRatios, error) {
+				return c.GetPriceEarningRatioContext(ctx, day.Format("02"), day.Format("01"), day.Format("2006"))
+			}),
+		},
+		{
+			name: "company_details",
+			url:  c.url("/company/companydetails/" + healthcheckTradingCode),
+			run: func(ctx context.Context) (int, error) {
+				details, err := c.GetCompanyDetails(ctx, healthcheckTradingCode)
+				if err != nil {
+					return 0, err
+				}
+				if details.CompanyName == "" || details.TotalSecurities <= 0 {
+					return 1, errImplausible(c.url("/company/companydetails/"+healthcheckTradingCode), "empty company name or no securities")
+				}
+				return 1, nil
+			},
+		},
+		{
+			name: "index_snapshot",
+			url:  c.url("/"),
+			run: func(ctx context.Context) (int, error) {
+				snapshot, err := c.GetIndexSnapshot(ctx)
+				if err != nil {
+					return 0, err
+				}
+				for _, name := range []string{"CASPI", "CSE30"} {
+					i := snapshot.Index(name)
+					if i == nil {
+						return len(snapshot.Indices), errImplausible(c.url("/"), "no %s index", name)
+					}
+					if err := validateIndex(c.url("/"), name, i.Value); err != nil {
+						return len(snapshot.Indices), err
+					}
+				}
+				if snapshot.TotalTrade < 0 || snapshot.TotalVolume < 0 {
+					return len(snapshot.Indices), errImplausible(c.url("/"), "negative total trade or volume")
+				}
+				return len(snapshot.Indices), nil
+			},
+		},
+	}
+	return runHealthChecks(ctx, "CSE", checks)
+}
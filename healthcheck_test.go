@@ -0,0 +1,124 @@
+package bdstockexchange
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_validatePrices(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    []priceRow
+		wantErr bool
+	}{
+		{"valid", []priceRow{{"GP", 300, 305, 298, 1000}, {"BATBC", 600, 610, 590, 20}}, false},
+		{"no rows", nil, true},
+		{"empty code", []priceRow{{"", 300, 305, 298, 1000}}, true},
+		{"duplicate code", []priceRow{{"GP", 300, 305, 298, 1000}, {"GP", 300, 305, 298, 1000}}, true},
+		{"high below low", []priceRow{{"GP", 300, 290, 298, 1000}}, true},
+		{"negative", []priceRow{{"GP", -1, 305, 298, 1000}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePrices("https://www.dsebd.org/", tt.rows)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validatePrices() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrLayoutChanged) {
+				t.Errorf("validatePrices() error = %v, want it to wrap %v", err, ErrLayoutChanged)
+			}
+		})
+	}
+}
+
+func TestDSE_Healthcheck(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	report := NewDSE(WithBaseURL(ts.URL)).Healthcheck(context.Background())
+	if report.Healthy() {
+		t.Fatal("HealthReport.Healthy() = true, want false")
+	}
+	if len(report.Endpoints) != 16 {
+		t.Fatalf("len(HealthReport.Endpoints) = %d, want 16", len(report.Endpoints))
+	}
+	for _, e := range report.Endpoints {
+		if e.Healthy || !errors.Is(e.Err, ErrHTTPStatus) {
+			t.Errorf("endpoint %s: healthy = %v, error = %v, want %v", e.Name, e.Healthy, e.Err, ErrHTTPStatus)
+		}
+	}
+}
+
+// testClock returns the time the pages in testdata were recorded at
+func testClock() time.Time {
+	return time.Date(2020, time.October, 15, 15, 10, 0, 0, Dhaka)
+}
+
+func TestDSE_Healthcheck_fixtures(t *testing.T) {
+	dse := NewDSE(WithHTTPClient(&http.Client{Transport: testTransport()}), WithClock(testClock))
+	report := dse.Healthcheck(context.Background())
+	for _, e := range report.Endpoints {
+		if !e.Healthy {
+			t.Errorf("endpoint %s is not healthy: %v", e.Name, e.Err)
+		}
+		if e.Rows == 0 {
+			t.Errorf("endpoint %s has no rows", e.Name)
+		}
+	}
+}
+
+func TestCSE_Healthcheck(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	report := NewCSE(WithBaseURL(ts.URL)).Healthcheck(context.Background())
+	if report.Healthy() {
+		t.Fatal("HealthReport.Healthy() = true, want false")
+	}
+	if len(report.Endpoints) != 10 {
+		t.Fatalf("len(HealthReport.Endpoints) = %d, want 10", len(report.Endpoints))
+	}
+	for _, e := range report.Endpoints {
+		if e.Healthy || !errors.Is(e.Err, ErrHTTPStatus) {
+			t.Errorf("endpoint %s: healthy = %v, error = %v, want %v", e.Name, e.Healthy, e.Err, ErrHTTPStatus)
+		}
+	}
+}
+
+func Test_runHealthChecks_panic(t *testing.T) {
+	checks := []healthCheck{
+		{name: "panics", url: "https://www.cse.com.bd/", run: func(ctx context.Context) (int, error) {
+			var n *int
+			return *n, nil
+		}},
+		{name: "works", url: "https://www.cse.com.bd/", run: func(ctx context.Context) (int, error) { return 1, nil }},
+	}
+	report := runHealthChecks(context.Background(), "CSE", checks)
+	if e := report.Endpoints[0]; e.Healthy || !errors.Is(e.Err, ErrLayoutChanged) {
+		t.Errorf("endpoint %s: healthy = %v, error = %v, want ErrLayoutChanged", e.Name, e.Healthy, e.Err)
+	}
+	if e := report.Endpoints[1]; !e.Healthy || e.Rows != 1 {
+		t.Errorf("endpoint %s after a panic: healthy = %v, rows = %d", e.Name, e.Healthy, e.Rows)
+	}
+}
+
+func TestCSE_Healthcheck_fixtures(t *testing.T) {
+	cse := NewCSE(WithHTTPClient(&http.Client{Transport: testTransport()}), WithClock(testClock))
+	report := cse.Healthcheck(context.Background())
+	for _, e := range report.Endpoints {
+		if !e.Healthy {
+			t.Errorf("endpoint %s is not healthy: %v", e.Name, e.Err)
+		}
+		if e.Rows == 0 {
+			t.Errorf("endpoint %s has no rows", e.Name)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package bdstockexchange
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// dseCategories are the categories of the dse in the order they are listed
+var dseCategories = []string{"A", "B", "G", "N", "Z"}
+
+// GetAllListedCompanies returns all the companies listed in dse or error in case of any error
+func (d *DSE) GetAllListedCompanies() ([]*Company, error) {
+	return d.GetAllListedCompaniesContext(context.Background())
+}
+
+// GetAllListedCompaniesContext is like GetAllListedCompanies but takes a context to cancel the request or stop the parsing
+func (d *DSE) GetAllListedCompaniesContext(ctx context.Context) ([]*Company, error) {
+	url := d.url("/company_listing.php")
+	doc, err := d.client.loadURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return readDSECompanies(ctx, doc, url)
+}
+
+// GetAllListedCompaniesByIndustry returns list of companies with their industry type or error in case of any error.
+// The companies of every industry are read from the page of the industry, so it sends a request per industry
+func (d *DSE) GetAllListedCompaniesByIndustry() ([]*CompanyListingByIndustry, error) {
+	return d.GetAllListedCompaniesByIndustryContext(context.Background())
+}
+
+// GetAllListedCompaniesByIndustryContext is like GetAllListedCompaniesByIndustry but takes a context to cancel the request or stop the parsing
+func (d *DSE) GetAllListedCompaniesByIndustryContext(ctx context.Context) ([]*CompanyListingByIndustry, error) {
+	listURL := d.url("/by_industrylisting.php")
+	doc, err := d.client.loadURL(ctx, listURL)
+	if err != nil {
+		return nil, err
+	}
+
+	links := htmlquery.Find(doc, `//a[contains(@href, "companylistbyindustry.php")]`)
+	if len(links) == 0 {
+		return nil, errLayoutChanged(listURL, "industry links")
+	}
+
+	listing := make([]*CompanyListingByIndustry, 0, len(links))
+	for _, a := range links {
+		industryNo := queryParam(htmlquery.SelectAttr(a, "href"), "industryno")
+		if industryNo == "" {
+			return nil, errLayoutChanged(listURL, "industry number")
+		}
+		url := d.url("/companylistbyindustry.php?industryno=" + industryNo)
+		page, err := d.client.loadURL(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		list, err := readDSECompanies(ctx, page, url)
+		if err != nil {
+			return nil, err
+		}
+		listing = append(listing, &CompanyListingByIndustry{
+			IndustryType: cleanText(htmlquery.InnerText(a)),
+			List:         list,
+		})
+	}
+	return listing, nil
+}
+
+// GetAllListedCompaniesByCategory returns the listing of the companies by their category or an error in case of any error.
+// The trading codes are read from the latest share prices of every category and the names from GetAllListedCompanies.
+// A category without any company is left out and a company missing from the listed companies has an empty name
+func (d *DSE) GetAllListedCompaniesByCategory() ([]*CompanyListingByCategory, error) {
+	return d.GetAllListedCompaniesByCategoryContext(context.Background())
+}
+
+// GetAllListedCompaniesByCategoryContext is like GetAllListedCompaniesByCategory but takes a context to cancel the request or stop the parsing
+func (d *DSE) GetAllListedCompaniesByCategoryContext(ctx context.Context) ([]*CompanyListingByCategory, error) {
+	companies, err := d.GetAllListedCompaniesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(companies))
+	for _, c := range companies {
+		names[c.TradingCode] = c.CompanyName
+	}
+
+	listing := make([]*CompanyListingByCategory, 0, len(dseCategories))
+	for _, category := range dseCategories {
+		shares, err := d.getLatestPrices(ctx, d.url(fmt.Sprintf("/latest_share_price_all_group.php?group=%s", category)))
+		if err != nil {
+			return nil, err
+		}
+		if len(shares) == 0 {
+			continue
+		}
+		list := make([]*Company, 0, len(shares))
+		for _, s := range shares {
+			list = append(list, &Company{
+				CompanyName: names[s.TradingCode],
+				TradingCode: s.TradingCode,
+			})
+		}
+		listing = append(listing, &CompanyListingByCategory{
+			Category: category,
+			List:     list,
+		})
+	}
+	return listing, nil
+}
+
+// readDSECompanies returns the companies linked to their dse company page in the order they are printed.
+// The name of a company is printed in brackets in a span inside or right after the link,
+// ex: <a href="displayCompany.php?name=GP">GP</a> <span>(Grameenphone Ltd.)</span>
+func readDSECompanies(ctx context.Context, doc *html.Node, url string) ([]*Company, error) {
+	links := htmlquery.Find(doc, `//a[contains(@href, "displayCompany.php")]`)
+	if len(links) == 0 {
+		return nil, errLayoutChanged(url, "company links")
+	}
+
+	companies := make([]*Company, 0, len(links))
+	seen := make(map[string]bool, len(links))
+	for _, a := range links {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		code := strings.TrimSpace(queryParam(htmlquery.SelectAttr(a, "href"), "name"))
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		companies = append(companies, &Company{
+			CompanyName: dseCompanyName(a),
+			TradingCode: code,
+		})
+	}
+	return companies, nil
+}
+
+// dseCompanyName returns the name printed with the company link or an empty string if there is none
+func dseCompanyName(a *html.Node) string {
+	span := htmlquery.FindOne(a, "span")
+	if span == nil {
+		for n := a.NextSibling; n != nil; n = n.NextSibling {
+			if n.Type == html.ElementNode {
+				if n.Data == "span" {
+					span = n
+				}
+				break
+			}
+		}
+	}
+	if span == nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.Trim(cleanText(htmlquery.InnerText(span)), "()"))
+}
+
+// queryParam returns the value of the query parameter of the link or an empty string if the link has none,
+// ex: "displayCompany.php?name=GP" has the name GP
+func queryParam(link, name string) string {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get(name)
+}
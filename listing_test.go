@@ -0,0 +1,83 @@
+package bdstockexchange
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/antchfx/htmlquery"
+)
+
+func TestDSE_GetAllListedCompanies(t *testing.T) {
+	got, err := testDSE().GetAllListedCompanies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "dse_listed_companies", got)
+}
+
+func TestDSE_GetAllListedCompaniesByIndustry(t *testing.T) {
+	got, err := testDSE().GetAllListedCompaniesByIndustry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "dse_listed_companies_by_industry", got)
+}
+
+func TestDSE_GetAllListedCompaniesByCategory(t *testing.T) {
+	got, err := testDSE().GetAllListedCompaniesByCategory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "dse_listed_companies_by_category", got)
+}
+
+func TestDSE_GetAllListedCompaniesContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := testDSE().GetAllListedCompaniesContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("DSE.GetAllListedCompaniesContext() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func Test_readDSECompanies(t *testing.T) {
+	tests := []struct {
+		name    string
+		page    string
+		want    []Company
+		wantErr error
+	}{
+		{"name after the link", `<a href="displayCompany.php?name=GP">GP</a> <span>(Grameenphone Ltd.)</span>`,
+			[]Company{{"Grameenphone Ltd.", "GP"}}, nil},
+		{"name in the link", `<a href="displayCompany.php?name=ACI">ACI <span>(ACI Limited)</span></a>`,
+			[]Company{{"ACI Limited", "ACI"}}, nil},
+		{"no name", `<a href="displayCompany.php?name=GP">GP</a><br><span>(Other)</span>`,
+			[]Company{{"", "GP"}}, nil},
+		{"repeated", `<a href="displayCompany.php?name=GP">GP</a><a href="displayCompany.php?name=GP">GP</a>`,
+			[]Company{{"", "GP"}}, nil},
+		{"no links", `<a href="index.php">Home</a>`, nil, ErrLayoutChanged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := htmlquery.Parse(strings.NewReader(tt.page))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := readDSECompanies(context.Background(), doc, "company_listing.php")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("readDSECompanies() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("readDSECompanies() = %d companies, want %d", len(got), len(tt.want))
+			}
+			for i, c := range got {
+				if *c != tt.want[i] {
+					t.Errorf("readDSECompanies()[%d] = %+v, want %+v", i, *c, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package bdstockexchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// dsePriceEarningColumns are the columns of the dse price earning ratio report. P/E 1 is based on the last audited
+// accounts and P/E 2, 3 and 4 on the annualized eps of the first quarter, the half year and the third quarter
+var dsePriceEarningColumns = []column{
+	{"sl", []string{"#", "SL", "SL."}},
+	{"trading_code", []string{"TRADE CODE", "TRADING CODE", "CODE"}},
+	{"close_price", []string{"CLOSE PRICE", "CLOSEP"}},
+	{"pe_audited", []string{"P/E 1"}},
+	{"pe_quarter1", []string{"P/E 2"}},
+	{"pe_half_year", []string{"P/E 3"}},
+	{"pe_quarter3", []string{"P/E 4"}},
+}
+
+// GetPriceEarningRatio returns the price earning ratios of the listed companies on the day of the date in the Dhaka
+// location. The dse does not print the eps, so the EPS fields are 0 and PERatioBasedOnAnnualizedEPS is the ratio of
+// the latest interim accounts. It returns an error wrapping ErrNoDataFound if the market was closed on the day
+func (d *DSE) GetPriceEarningRatio(ctx context.Context, date time.Time) (*PriceEarningRatios, error) {
+	date = dhakaDate(date)
+	url := d.url("/pe_archive.php?date=" + date.Format("2006-01-02"))
+	doc, err := d.client.loadURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := findTable(doc, url, dsePriceEarningColumns)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.rows) == 0 {
+		return nil, fmt.Errorf("%w: no price earning ratio on %s", ErrNoDataFound, date.Format("2006-01-02"))
+	}
+
+	ratios := make([]*PriceEarningRatio, 0, len(t.rows))
+	p := &cellParser{url: url}
+	for row, cells := range t.rows {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		p.row = row
+		r := &PriceEarningRatio{
+			SL:                                strings.TrimSuffix(t.text(cells, "sl"), "."),
+			TradingCode:                       t.text(cells, "trading_code"),
+			ClosePrice:                        p.decimal("close_price", t.text(cells, "close_price")),
+			PERatioBasedOnLastAuditedAccounts: p.float64("pe_audited", t.text(cells, "pe_audited")),
+		}
+		interim := &r.PERatioAsPerUpdatedUnAuditedAccounts
+		interim.Quarter1 = p.float64("pe_quarter1", t.text(cells, "pe_quarter1"))
+		interim.HalfYear = p.float64("pe_half_year", t.text(cells, "pe_half_year"))
+		interim.Quarter3 = p.float64("pe_quarter3", t.text(cells, "pe_quarter3"))
+		for _, pe := range []float64{interim.Quarter3, interim.HalfYear, interim.Quarter1} {
+			if pe != 0 {
+				r.PERatioBasedOnAnnualizedEPS = pe
+				break
+			}
+		}
+		if p.err != nil {
+			return nil, p.err
+		}
+		ratios = append(ratios, r)
+	}
+
+	return &PriceEarningRatios{
+		Date:                   date.Format("2006-01-02"),
+		Time:                   date,
+		PriceEarningRatioArray: ratios,
+	}, nil
+}
+
+// PriceEarningRatioPoint is the price earning ratio of a company on a trading day
+type PriceEarningRatioPoint struct {
+	// Time is the trading day at midnight in the Dhaka location
+	Time time.Time
+	*PriceEarningRatio
+}
+
+// PriceEarningRatioSeries holds the price earning ratios of a company on the trading days of a range, the oldest first
+type PriceEarningRatioSeries struct {
+	TradingCode string
+	Points      []*PriceEarningRatioPoint
+}
+
+// GetPriceEarningRatioRange returns the price earning ratio series of every company from the from to the to date
+// inclusive, sorted by trading code. The days are taken in the Dhaka location. The weekend, Friday and Saturday, is
+// skipped and a day the cse has no ratios for, ex: a holiday, is left out. It returns an error wrapping
+// ErrInvalidArgument for a range which ends before it starts and ErrNoDataFound if there is no ratio in the range
+func (c *CSE) GetPriceEarningRatioRange(ctx context.Context, from, to time.Time) ([]*PriceEarningRatioSeries, error) {
+	from, to = dhakaDate(from), dhakaDate(to)
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: the range ends on %s before it starts on %s", ErrInvalidArgument,
+			to.Format("2006-01-02"), from.Format("2006-01-02"))
+	}
+
+	series := make(map[string]*PriceEarningRatioSeries)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() == time.Friday || day.Weekday() == time.Saturday {
+			continue
+		}
+		ratios, err := c.getPriceEarningRatio(ctx, day)
+		if errors.Is(err, ErrNoDataFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range ratios.PriceEarningRatioArray {
+			code := strings.TrimSpace(r.TradingCode)
+			s, ok := series[code]
+			if !ok {
+				s = &PriceEarningRatioSeries{TradingCode: code}
+				series[code] = s
+			}
+			s.Points = append(s.Points, &PriceEarningRatioPoint{Time: ratios.Time, PriceEarningRatio: r})
+		}
+	}
+	if len(series) == 0 {
+		return nil, fmt.Errorf("%w: no price earning ratio from %s to %s", ErrNoDataFound,
+			from.Format("2006-01-02"), to.Format("2006-01-02"))
+	}
+
+	list := make([]*PriceEarningRatioSeries, 0, len(series))
+	for _, s := range series {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].TradingCode < list[j].TradingCode
+	})
+	return list, nil
+}
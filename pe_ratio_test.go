@@ -0,0 +1,60 @@
+package bdstockexchange
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDSE_GetPriceEarningRatio(t *testing.T) {
+	tests := []struct {
+		name    string
+		date    time.Time
+		wantErr error
+	}{
+		{"trading day", time.Date(2020, time.October, 15, 12, 0, 0, 0, time.UTC), nil},
+		{"holiday", time.Date(2020, time.October, 16, 0, 0, 0, 0, Dhaka), ErrNoDataFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testDSE().GetPriceEarningRatio(context.Background(), tt.date)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DSE.GetPriceEarningRatio() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				assertGolden(t, "dse_price_earning_ratio", got)
+			}
+		})
+	}
+}
+
+func TestCSE_GetPriceEarningRatioRange(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2020, time.October, d, 0, 0, 0, 0, Dhaka)
+	}
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     int
+		wantErr  error
+	}{
+		{"weekend and holiday", day(14), day(18), 4, nil},
+		{"only holidays", day(16), day(18), 0, ErrNoDataFound},
+		{"reversed range", day(18), day(14), 0, ErrInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testCSE().GetPriceEarningRatioRange(context.Background(), tt.from, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CSE.GetPriceEarningRatioRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Fatalf("CSE.GetPriceEarningRatioRange() = %d series, want %d", len(got), tt.want)
+			}
+			if err == nil {
+				assertGolden(t, "cse_price_earning_ratio_range", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,361 @@
+package bdstockexchange
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// queryable is implemented by the share price models a ShareQuery can filter and sort
+type queryable interface {
+	// field returns the numeric value of the field or false if the model does not have the field
+	field(by sortBy) (float64, bool)
+	tradingCode() string
+	// category returns the category of the share or false if the model does not have a category
+	category() (string, bool)
+}
+
+func (s *DSEShare) field(by sortBy) (float64, bool) {
+	switch by {
+	case SortByLTP:
+		return s.LTP.Float64(), true
+	case SortByHighPrice:
+		return s.High.Float64(), true
+	case SortByLowPrice:
+		return s.Low.Float64(), true
+	case SortByYCP:
+		return s.YCP.Float64(), true
+	case SortByNumberOfTrades:
+		return float64(s.Trade), true
+	case SortByValue:
+		return s.ValueInMN.Float64(), true
+	case SortByVolumeOfShare:
+		return float64(s.Volume), true
+	case SortByPriceChange:
+		return s.Change.Float64(), true
+	case SortByPercentageChange:
+		return percentageChange(s.LTP.Float64(), s.YCP.Float64()), true
+	}
+	return 0, false
+}
+
+func (s *DSEShare) tradingCode() string {
+	return s.TradingCode
+}
+
+// category returns the category of the share. It is only known for the shares of GetLatestPricesByCategory
+func (s *DSEShare) category() (string, bool) {
+	return s.Category, s.Category != ""
+}
+
+func (s *CSEShare) field(by sortBy) (float64, bool) {
+	switch by {
+	case SortByLTP:
+		return s.LTP.Float64(), true
+	case SortByOpeningPrice:
+		return s.Open.Float64(), true
+	case SortByHighPrice:
+		return s.High.Float64(), true
+	case SortByLowPrice:
+		return s.Low.Float64(), true
+	case SortByYCP:
+		return s.YCP.Float64(), true
+	case SortByNumberOfTrades:
+		return float64(s.Trade), true
+	case SortByValue:
+		return s.ValueInMN.Float64(), true
+	case SortByVolumeOfShare:
+		return float64(s.Volume), true
+	case SortByPriceChange:
+		return s.Change().Float64(), true
+	case SortByPercentageChange:
+		return s.PercentageChange(), true
+	}
+	return 0, false
+}
+
+func (s *CSEShare) tradingCode() string {
+	return s.TradingCode
+}
+
+func (s *CSEShare) category() (string, bool) {
+	return "", false
+}
+
+func (s *LatestPricesWithPercentage) field(by sortBy) (float64, bool) {
+	switch by {
+	case SortByLTP:
+		return s.LTP.Float64(), true
+	case SortByHighPrice:
+		return s.High.Float64(), true
+	case SortByLowPrice:
+		return s.Low.Float64(), true
+	case SortByYCP:
+		return s.YCP.Float64(), true
+	case SortByNumberOfTrades:
+		return float64(s.Trade), true
+	case SortByValue:
+		return s.ValueInMN.Float64(), true
+	case SortByVolumeOfShare:
+		return float64(s.Volume), true
+	case SortByPriceChange:
+		return s.LTP.Sub(s.YCP).Float64(), true
+	case SortByPercentageChange:
+		return s.PercentageChange, true
+	}
+	return 0, false
+}
+
+func (s *LatestPricesWithPercentage) tradingCode() string {
+	return s.TradingCode
+}
+
+func (s *LatestPricesWithPercentage) category() (string, bool) {
+	return "", false
+}
+
+// percentageChange returns the change of the last trade price from yesterday's closing price in percent
+func percentageChange(ltp, ycp float64) float64 {
+	if ycp == 0 {
+		return 0
+	}
+	return (ltp - ycp) / ycp * 100
+}
+
+// Filter is a condition a share must meet to be kept by a ShareQuery. Use GreaterThan, LessThan, Between,
+// TradingCodeIn or CategoryIn to create one. A query with the zero Filter returns an error wrapping ErrInvalidArgument
+type Filter struct {
+	name  string
+	match func(s queryable) (bool, error)
+}
+
+// numericFilter returns a Filter comparing the field with the cmp function
+func numericFilter(name string, by sortBy, cmp func(v float64) bool) Filter {
+	return Filter{name: name, match: func(s queryable) (bool, error) {
+		v, ok := s.field(by)
+		if !ok {
+			return false, fmt.Errorf("%w: %T has no field to filter %s", ErrInvalidSortBy, s, name)
+		}
+		return cmp(v), nil
+	}}
+}
+
+// GreaterThan returns a Filter keeping the shares whose field is greater than the value, ex: GreaterThan(SortByLTP, 50)
+func GreaterThan(by sortBy, value float64) Filter {
+	return numericFilter("greater than", by, func(v float64) bool {
+		return v > value
+	})
+}
+
+// LessThan returns a Filter keeping the shares whose field is less than the value, ex: LessThan(SortByYCP, 100)
+func LessThan(by sortBy, value float64) Filter {
+	return numericFilter("less than", by, func(v float64) bool {
+		return v < value
+	})
+}
+
+// Between returns a Filter keeping the shares whose field is between min and max inclusive
+func Between(by sortBy, min, max float64) Filter {
+	return numericFilter("between", by, func(v float64) bool {
+		return v >= min && v <= max
+	})
+}
+
+// TradingCodeIn returns a Filter keeping the shares with one of the trading codes. The codes are matched case insensitively
+func TradingCodeIn(codes ...string) Filter {
+	return Filter{name: "trading code in", match: func(s queryable) (bool, error) {
+		for _, code := range codes {
+			if strings.EqualFold(code, s.tradingCode()) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}}
+}
+
+// CategoryIn returns a Filter keeping the shares of one of the categories, ex: CategoryIn("A", "B").
+// Only the DSEShare has a category, which is set by GetLatestPricesByCategory. The query returns an error wrapping
+// ErrInvalidArgument for a share without a category
+func CategoryIn(categories ...string) Filter {
+	return Filter{name: "category in", match: func(s queryable) (bool, error) {
+		category, ok := s.category()
+		if !ok {
+			return false, fmt.Errorf("%w: %T %s has no category", ErrInvalidArgument, s, s.tradingCode())
+		}
+		for _, c := range categories {
+			if strings.EqualFold(c, category) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}}
+}
+
+// sortKey is a field and the order a ShareQuery sorts by
+type sortKey struct {
+	by    sortBy
+	order sortOrder
+}
+
+// ShareQuery filters, sorts and limits a list of DSEShare, CSEShare or LatestPricesWithPercentage.
+// It is built with Query, ex: Query().Where(GreaterThan(SortByLTP, 50)).SortBy(SortByValue, DESC).ThenBy(SortByTradingCode, ASC).Limit(20)
+type ShareQuery struct {
+	filters []Filter
+	keys    []sortKey
+	limit   int
+}
+
+// Query returns a new ShareQuery which keeps every share in the order it is given
+func Query() *ShareQuery {
+	return &ShareQuery{}
+}
+
+// Where adds a filter to the query. A share is kept only if it matches every filter
+func (q *ShareQuery) Where(f Filter) *ShareQuery {
+	q.filters = append(q.filters, f)
+	return q
+}
+
+// SortBy sorts the result by the field in the order. It replaces the sort keys added before
+func (q *ShareQuery) SortBy(by sortBy, order sortOrder) *ShareQuery {
+	q.keys = []sortKey{{by, order}}
+	return q
+}
+
+// ThenBy adds a sort key used for the shares which are equal by the keys added before
+func (q *ShareQuery) ThenBy(by sortBy, order sortOrder) *ShareQuery {
+	q.keys = append(q.keys, sortKey{by, order})
+	return q
+}
+
+// Limit keeps at most n shares of the result. A limit of 0 keeps every share
+func (q *ShareQuery) Limit(n int) *ShareQuery {
+	q.limit = n
+	return q
+}
+
+// DSEShares runs the query on the dse shares and returns the result as a new slice
+func (q *ShareQuery) DSEShares(arr []*DSEShare) ([]*DSEShare, error) {
+	in := make([]queryable, len(arr))
+	for i, s := range arr {
+		in[i] = s
+	}
+	out, err := q.run(in, &DSEShare{})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*DSEShare, len(out))
+	for i, s := range out {
+		result[i] = s.(*DSEShare)
+	}
+	return result, nil
+}
+
+// CSEShares runs the query on the cse shares and returns the result as a new slice
+func (q *ShareQuery) CSEShares(arr []*CSEShare) ([]*CSEShare, error) {
+	in := make([]queryable, len(arr))
+	for i, s := range arr {
+		in[i] = s
+	}
+	out, err := q.run(in, &CSEShare{})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*CSEShare, len(out))
+	for i, s := range out {
+		result[i] = s.(*CSEShare)
+	}
+	return result, nil
+}
+
+// LatestPricesWithPercentage runs the query on the dse prices with percentage change and returns the result as a new slice
+func (q *ShareQuery) LatestPricesWithPercentage(arr []*LatestPricesWithPercentage) ([]*LatestPricesWithPercentage, error) {
+	in := make([]queryable, len(arr))
+	for i, s := range arr {
+		in[i] = s
+	}
+	out, err := q.run(in, &LatestPricesWithPercentage{})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*LatestPricesWithPercentage, len(out))
+	for i, s := range out {
+		result[i] = s.(*LatestPricesWithPercentage)
+	}
+	return result, nil
+}
+
+// run validates the sort keys against the zero value of the model and filters, sorts and limits the shares
+func (q *ShareQuery) run(shares []queryable, zero queryable) ([]queryable, error) {
+	if q.limit < 0 {
+		return nil, fmt.Errorf("%w: negative limit %d", ErrInvalidArgument, q.limit)
+	}
+	for _, k := range q.keys {
+		if k.order != ASC && k.order != DESC {
+			return nil, ErrInvalidSortOrder
+		}
+		if _, ok := zero.field(k.by); !ok && k.by != SortByTradingCode {
+			return nil, ErrInvalidSortBy
+		}
+	}
+	for _, f := range q.filters {
+		if f.match == nil {
+			return nil, fmt.Errorf("%w: empty filter, use GreaterThan, LessThan, Between, TradingCodeIn or CategoryIn", ErrInvalidArgument)
+		}
+	}
+
+	result := make([]queryable, 0, len(shares))
+	for _, s := range shares {
+		keep := true
+		for _, f := range q.filters {
+			ok, err := f.match(s)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			result = append(result, s)
+		}
+	}
+
+	if len(q.keys) > 0 {
+		sort.SliceStable(result, func(i, j int) bool {
+			for _, k := range q.keys {
+				c := compareShares(result[i], result[j], k.by)
+				if c == 0 {
+					continue
+				}
+				if k.order == DESC {
+					return c > 0
+				}
+				return c < 0
+			}
+			return false
+		})
+	}
+
+	if q.limit > 0 && len(result) > q.limit {
+		result = result[:q.limit]
+	}
+	return result, nil
+}
+
+// compareShares returns -1, 0 or 1 if the field of a is less than, equal to or greater than the field of b
+func compareShares(a, b queryable, by sortBy) int {
+	if by == SortByTradingCode {
+		return strings.Compare(a.tradingCode(), b.tradingCode())
+	}
+	x, _ := a.field(by)
+	y, _ := b.field(by)
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
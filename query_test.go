@@ -0,0 +1,113 @@
+package bdstockexchange
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func codes(arr interface{}) []string {
+	result := make([]string, 0)
+	switch arr := arr.(type) {
+	case []*DSEShare:
+		for _, s := range arr {
+			result = append(result, s.TradingCode)
+		}
+	case []*CSEShare:
+		for _, s := range arr {
+			result = append(result, s.TradingCode)
+		}
+	case []*LatestPricesWithPercentage:
+		for _, s := range arr {
+			result = append(result, s.TradingCode)
+		}
+	}
+	return result
+}
+
+func TestShareQuery_DSEShares(t *testing.T) {
+	shares := []*DSEShare{
+		{TradingCode: "ACI", LTP: MustParseDecimal("214.5"), YCP: MustParseDecimal("213.2"), Change: MustParseDecimal("1.3"), ValueInMN: MustParseDecimal("28.4"), Category: "A"},
+		{TradingCode: "BATBC", LTP: MustParseDecimal("1105.3"), YCP: MustParseDecimal("1101.6"), Change: MustParseDecimal("3.7"), ValueInMN: MustParseDecimal("45.1"), Category: "A"},
+		{TradingCode: "BEXIMCO", LTP: MustParseDecimal("41.2"), YCP: MustParseDecimal("40.6"), Change: MustParseDecimal("0.6"), ValueInMN: MustParseDecimal("45.1"), Category: "A"},
+		{TradingCode: "EMERALDOIL", LTP: MustParseDecimal("13.1"), YCP: MustParseDecimal("13.5"), Change: MustParseDecimal("-0.4"), ValueInMN: MustParseDecimal("3.1"), Category: "Z"},
+	}
+	tests := []struct {
+		name    string
+		q       *ShareQuery
+		want    []string
+		wantErr error
+	}{
+		{"no query", Query(), []string{"ACI", "BATBC", "BEXIMCO", "EMERALDOIL"}, nil},
+		{"where", Query().Where(GreaterThan(SortByLTP, 50)).Where(CategoryIn("a")), []string{"ACI", "BATBC"}, nil},
+		{"between", Query().Where(Between(SortByPriceChange, -1, 1)), []string{"BEXIMCO", "EMERALDOIL"}, nil},
+		{"less than", Query().Where(LessThan(SortByPercentageChange, 0)), []string{"EMERALDOIL"}, nil},
+		{"trading code", Query().Where(TradingCodeIn("gp", "aci")), []string{"ACI"}, nil},
+		{"sort then by", Query().SortBy(SortByValue, DESC).ThenBy(SortByTradingCode, DESC), []string{"BEXIMCO", "BATBC", "ACI", "EMERALDOIL"}, nil},
+		{"sort by replaces", Query().SortBy(SortByValue, DESC).SortBy(SortByTradingCode, DESC), []string{"EMERALDOIL", "BEXIMCO", "BATBC", "ACI"}, nil},
+		{"percentage change", Query().SortBy(SortByPercentageChange, DESC), []string{"BEXIMCO", "ACI", "BATBC", "EMERALDOIL"}, nil},
+		{"limit", Query().SortBy(SortByLTP, ASC).Limit(2), []string{"EMERALDOIL", "BEXIMCO"}, nil},
+		{"no opening price", Query().SortBy(SortByOpeningPrice, ASC), nil, ErrInvalidSortBy},
+		{"no opening price filter", Query().Where(GreaterThan(SortByOpeningPrice, 1)), nil, ErrInvalidSortBy},
+		{"invalid order", Query().SortBy(SortByLTP, sortOrder(5)), nil, ErrInvalidSortOrder},
+		{"negative limit", Query().Limit(-1), nil, ErrInvalidArgument},
+		{"zero filter", Query().Where(Filter{}), nil, ErrInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.q.DSEShares(shares)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ShareQuery.DSEShares() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if !reflect.DeepEqual(codes(got), tt.want) {
+				t.Errorf("ShareQuery.DSEShares() = %v, want %v", codes(got), tt.want)
+			}
+		})
+	}
+	if codes(shares)[0] != "ACI" || codes(shares)[3] != "EMERALDOIL" {
+		t.Errorf("ShareQuery.DSEShares() changed the input order to %v", codes(shares))
+	}
+
+	// the latest prices of all the shares do not have the category
+	latest, err := testDSE().GetLatestPrices(SortByTradingCode, ASC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Query().Where(CategoryIn("A")).DSEShares(latest); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("ShareQuery.DSEShares() with a category filter on shares without category error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestShareQuery_CSEShares(t *testing.T) {
+	shares, err := testCSE().GetLatestPrices(SortByTradingCode, ASC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Query().Where(GreaterThan(SortByOpeningPrice, 100)).SortBy(SortByOpeningPrice, DESC).CSEShares(shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"BATBC", "GP", "ACI", "SQURPHARMA"}; !reflect.DeepEqual(codes(got), want) {
+		t.Errorf("ShareQuery.CSEShares() = %v, want %v", codes(got), want)
+	}
+	if _, err := Query().Where(CategoryIn("A")).CSEShares(shares); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("ShareQuery.CSEShares() with a category filter error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestShareQuery_LatestPricesWithPercentage(t *testing.T) {
+	prices, err := testDSE().GetLatestPricesSortedByPercentageChange()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Query().Where(GreaterThan(SortByPercentageChange, 0)).SortBy(SortByTradingCode, ASC).Limit(3).LatestPricesWithPercentage(prices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ACI", "BATBC", "BEXIMCO"}; !reflect.DeepEqual(codes(got), want) {
+		t.Errorf("ShareQuery.LatestPricesWithPercentage() = %v, want %v", codes(got), want)
+	}
+}
@@ -0,0 +1,295 @@
+package bdstockexchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// companyNameSuffixes are the words left out of a company name when the names of two listings are compared
+var companyNameSuffixes = map[string]bool{
+	"THE": true, "LTD": true, "LIMITED": true, "PLC": true, "CO": true, "COMPANY": true, "INC": true,
+	"CORP": true, "CORPORATION": true,
+}
+
+// Listing is a security as it is listed on an exchange
+type Listing struct {
+	Exchange    string `json:"exchange"`
+	TradingCode string `json:"trading_code"`
+	CompanyName string `json:"company_name"`
+	Sector      string `json:"sector,omitempty"`
+	Category    string `json:"category,omitempty"`
+}
+
+// Security is an instrument with its listing on each exchange. DSE or CSE is nil if it is not listed there
+type Security struct {
+	// Name is the company name of the dse listing, or of the cse listing if it is only listed in cse
+	Name string   `json:"name"`
+	DSE  *Listing `json:"dse,omitempty"`
+	CSE  *Listing `json:"cse,omitempty"`
+}
+
+// listing returns the listing of the security on the exchange or nil
+func (s *Security) listing(exchange string) *Listing {
+	if exchange == ExchangeDSE {
+		return s.DSE
+	}
+	return s.CSE
+}
+
+// setListing sets the listing of the security on the exchange and updates its name
+func (s *Security) setListing(exchange string, l *Listing) {
+	if exchange == ExchangeDSE {
+		s.DSE = l
+	} else {
+		s.CSE = l
+	}
+	s.Name = ""
+	for _, l := range []*Listing{s.DSE, s.CSE} {
+		if l != nil && l.CompanyName != "" {
+			s.Name = l.CompanyName
+			break
+		}
+	}
+}
+
+// SecurityChanges are the listings added, updated and removed by SecurityMaster.Refresh
+type SecurityChanges struct {
+	Added   []*Listing `json:"added"`
+	Updated []*Listing `json:"updated"`
+	Removed []*Listing `json:"removed"`
+}
+
+// SecurityMaster links the listings of the dse and the cse which are the same instrument. A listing is linked to
+// the listing of the other exchange with the same trading code or else with the same company name, compared in
+// upper case without the punctuation and the words like Ltd. and Limited, ex: "Grameenphone Ltd." and
+// "GrameenPhone Limited" are the same. The zero value is an empty master and Refresh fills it in.
+// A SecurityMaster is not safe for concurrent use
+type SecurityMaster struct {
+	// RefreshedAt is the time of the last refresh of each exchange by its name, ex: ExchangeDSE
+	RefreshedAt map[string]time.Time `json:"refreshed_at"`
+	// Securities are the securities sorted by name
+	Securities []*Security `json:"securities"`
+}
+
+// LoadSecurityMaster reads a security master written by SecurityMaster.Save
+func LoadSecurityMaster(r io.Reader) (*SecurityMaster, error) {
+	m := &SecurityMaster{}
+	if err := json.NewDecoder(r).Decode(m); err != nil {
+		return nil, fmt.Errorf("read security master: %w", err)
+	}
+	for _, s := range m.Securities {
+		if s.DSE == nil && s.CSE == nil {
+			return nil, fmt.Errorf("read security master: security %q has no listing", s.Name)
+		}
+	}
+	return m, nil
+}
+
+// Save writes the security master as json, to be read back by LoadSecurityMaster
+func (m *SecurityMaster) Save(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "\t")
+	return e.Encode(m)
+}
+
+// Lookup returns the security listed on the exchange with the trading code or nil if there is none,
+// ex: Lookup(ExchangeCSE, "GP")
+func (m *SecurityMaster) Lookup(exchange, code string) *Security {
+	for _, s := range m.Securities {
+		if l := s.listing(exchange); l != nil && strings.EqualFold(l.TradingCode, strings.TrimSpace(code)) {
+			return s
+		}
+	}
+	return nil
+}
+
+// Refresh reads the listed companies of the dse and the cse with their industry and category and applies the changes
+// since the last refresh. A nil dse or cse is not refreshed, so the exchanges can be refreshed one at a time.
+// The links of the listings which are still listed are kept, a new listing is linked to a security not yet listed
+// on its exchange and a security is removed when it is listed on neither exchange.
+// Nothing is changed if reading the listings of an exchange fails
+func (m *SecurityMaster) Refresh(ctx context.Context, dse *DSE, cse *CSE) (*SecurityChanges, error) {
+	listings := make(map[string][]*Listing, 2)
+	if dse != nil {
+		industries, err := dse.GetAllListedCompaniesByIndustryContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		categories, err := dse.GetAllListedCompaniesByCategoryContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		listings[ExchangeDSE] = collectListings(ExchangeDSE, industries, categories)
+	}
+	if cse != nil {
+		industries, err := cse.GetAllListedCompaniesByIndustryContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		categories, err := cse.GetAllListedCompaniesByCategoryContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		listings[ExchangeCSE] = collectListings(ExchangeCSE, industries, categories)
+	}
+
+	changes := &SecurityChanges{}
+	for _, exchange := range []string{ExchangeDSE, ExchangeCSE} {
+		if l, ok := listings[exchange]; ok {
+			m.merge(exchange, l, changes)
+			if m.RefreshedAt == nil {
+				m.RefreshedAt = make(map[string]time.Time, 2)
+			}
+			m.RefreshedAt[exchange] = time.Now()
+		}
+	}
+	return changes, nil
+}
+
+// merge replaces the listings of the exchange in the master with the listings and records the changes
+func (m *SecurityMaster) merge(exchange string, listings []*Listing, changes *SecurityChanges) {
+	current := make(map[string]*Listing, len(listings))
+	for _, l := range listings {
+		current[normalizeCode(l.TradingCode)] = l
+	}
+
+	// the listings of the exchange which are gone are removed first, so a company listed under a new trading code
+	// can be linked again by its name
+	kept := make(map[string]bool, len(listings))
+	securities := m.Securities[:0]
+	for _, s := range m.Securities {
+		old := s.listing(exchange)
+		if old != nil {
+			l, ok := current[normalizeCode(old.TradingCode)]
+			switch {
+			case !ok:
+				changes.Removed = append(changes.Removed, old)
+				s.setListing(exchange, nil)
+			case *l != *old:
+				changes.Updated = append(changes.Updated, l)
+				s.setListing(exchange, l)
+			default:
+				s.setListing(exchange, l)
+			}
+			if ok {
+				kept[normalizeCode(l.TradingCode)] = true
+			}
+		}
+		if s.DSE != nil || s.CSE != nil {
+			securities = append(securities, s)
+		}
+	}
+	m.Securities = securities
+
+	for _, l := range listings {
+		if kept[normalizeCode(l.TradingCode)] {
+			continue
+		}
+		changes.Added = append(changes.Added, l)
+		s := m.match(exchange, l)
+		if s == nil {
+			s = &Security{}
+			m.Securities = append(m.Securities, s)
+		}
+		s.setListing(exchange, l)
+	}
+
+	sort.SliceStable(m.Securities, func(i, j int) bool {
+		return strings.ToUpper(m.Securities[i].Name) < strings.ToUpper(m.Securities[j].Name)
+	})
+}
+
+// match returns the security not yet listed on the exchange which has a listing with the same trading code as l,
+// or else with the same company name. It returns nil if there is none
+func (m *SecurityMaster) match(exchange string, l *Listing) *Security {
+	code, name := normalizeCode(l.TradingCode), normalizeCompanyName(l.CompanyName)
+	var byName *Security
+	for _, s := range m.Securities {
+		if s.listing(exchange) != nil {
+			continue
+		}
+		for _, other := range []*Listing{s.DSE, s.CSE} {
+			if other == nil {
+				continue
+			}
+			if normalizeCode(other.TradingCode) == code {
+				return s
+			}
+			if byName == nil && name != "" && normalizeCompanyName(other.CompanyName) == name {
+				byName = s
+			}
+		}
+	}
+	return byName
+}
+
+// collectListings returns the listings of the exchange joined from its listings by industry and by category,
+// sorted by trading code
+func collectListings(exchange string, industries []*CompanyListingByIndustry, categories []*CompanyListingByCategory) []*Listing {
+	byCode := make(map[string]*Listing)
+	add := func(c *Company) *Listing {
+		code := strings.TrimSpace(c.TradingCode)
+		l, ok := byCode[code]
+		if !ok {
+			l = &Listing{Exchange: exchange, TradingCode: code}
+			byCode[code] = l
+		}
+		if l.CompanyName == "" {
+			l.CompanyName = strings.TrimSpace(c.CompanyName)
+		}
+		return l
+	}
+	for _, industry := range industries {
+		for _, c := range industry.List {
+			add(c).Sector = industry.IndustryType
+		}
+	}
+	for _, category := range categories {
+		for _, c := range category.List {
+			add(c).Category = category.Category
+		}
+	}
+
+	listings := make([]*Listing, 0, len(byCode))
+	for _, l := range byCode {
+		if l.TradingCode != "" {
+			listings = append(listings, l)
+		}
+	}
+	sort.Slice(listings, func(i, j int) bool {
+		return listings[i].TradingCode < listings[j].TradingCode
+	})
+	return listings
+}
+
+// normalizeCode returns the trading code in upper case without any character but the letters and digits
+func normalizeCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, code)
+}
+
+// normalizeCompanyName returns the words of the company name in upper case without the punctuation and the words
+// like Ltd. and Limited, joined without spaces, ex: "Grameen Phone Ltd." becomes "GRAMEENPHONE"
+func normalizeCompanyName(name string) string {
+	name = strings.Replace(strings.ToUpper(name), "&", " AND ", -1)
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, w := range words {
+		if !companyNameSuffixes[w] {
+			b.WriteString(w)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,123 @@
+package bdstockexchange
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSecurityMaster_Refresh(t *testing.T) {
+	m := &SecurityMaster{}
+	changes, err := m.Refresh(context.Background(), testDSE(), testCSE())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes.Added) != 15 || len(changes.Updated) != 0 || len(changes.Removed) != 0 {
+		t.Errorf("Refresh() changes = %d added, %d updated, %d removed, want 15, 0, 0",
+			len(changes.Added), len(changes.Updated), len(changes.Removed))
+	}
+	if len(m.RefreshedAt) != 2 {
+		t.Errorf("Refresh() RefreshedAt = %v, want both exchanges", m.RefreshedAt)
+	}
+	m.RefreshedAt = nil
+	assertGolden(t, "security_master", m)
+
+	if s := m.Lookup(ExchangeCSE, "gp"); s == nil || s.DSE == nil || s.DSE.Sector != "Telecommunication" {
+		t.Errorf("Lookup(CSE, gp) = %+v, want GP linked to the dse", s)
+	}
+
+	var b bytes.Buffer
+	if err := m.Save(&b); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSecurityMaster(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "security_master", loaded)
+
+	changes, err = loaded.Refresh(context.Background(), nil, testCSE())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes.Added)+len(changes.Updated)+len(changes.Removed) != 0 {
+		t.Errorf("second Refresh() changes = %+v, want none", changes)
+	}
+	if _, ok := loaded.RefreshedAt[ExchangeDSE]; ok {
+		t.Errorf("Refresh() of the cse set the dse RefreshedAt")
+	}
+}
+
+func TestSecurityMaster_merge(t *testing.T) {
+	listing := func(exchange, code, name, category string) *Listing {
+		return &Listing{Exchange: exchange, TradingCode: code, CompanyName: name, Category: category}
+	}
+	linked := func(m *SecurityMaster, dse, cse string) bool {
+		s := m.Lookup(ExchangeDSE, dse)
+		return s != nil && s.CSE != nil && s.CSE.TradingCode == cse
+	}
+
+	m := &SecurityMaster{}
+	m.merge(ExchangeDSE, []*Listing{
+		listing(ExchangeDSE, "GP", "Grameenphone Ltd.", "A"),
+		listing(ExchangeDSE, "BXPHARMA", "Beximco Pharmaceuticals Ltd.", "A"),
+	}, &SecurityChanges{})
+	m.merge(ExchangeCSE, []*Listing{
+		listing(ExchangeCSE, "GP", "GrameenPhone Limited", "A"),
+		listing(ExchangeCSE, "BXPHAR", "Beximco Pharmaceuticals Limited", "A"),
+		listing(ExchangeCSE, "CVOPRL", "CVO Petrochemical Refinery Limited", "A"),
+	}, &SecurityChanges{})
+	if len(m.Securities) != 3 || !linked(m, "GP", "GP") || !linked(m, "BXPHARMA", "BXPHAR") {
+		t.Fatalf("merge() = %d securities, want GP and BXPHARMA linked", len(m.Securities))
+	}
+
+	// a new trading code is linked again by name and a changed category is an update
+	changes := &SecurityChanges{}
+	m.merge(ExchangeCSE, []*Listing{
+		listing(ExchangeCSE, "GP", "GrameenPhone Limited", "B"),
+		listing(ExchangeCSE, "BEXPHARMA", "Beximco Pharmaceuticals Limited", "A"),
+		listing(ExchangeCSE, "CVOPRL", "CVO Petrochemical Refinery Limited", "A"),
+	}, changes)
+	if len(changes.Added) != 1 || len(changes.Updated) != 1 || len(changes.Removed) != 1 {
+		t.Errorf("merge() changes = %+v, want 1 added, 1 updated and 1 removed", changes)
+	}
+	if !linked(m, "BXPHARMA", "BEXPHARMA") || m.Lookup(ExchangeCSE, "GP").CSE.Category != "B" {
+		t.Errorf("merge() did not update the cse listings")
+	}
+
+	// a security listed on neither exchange is removed
+	m.merge(ExchangeCSE, []*Listing{listing(ExchangeCSE, "GP", "GrameenPhone Limited", "B")}, &SecurityChanges{})
+	m.merge(ExchangeDSE, []*Listing{listing(ExchangeDSE, "BXPHARMA", "Beximco Pharmaceuticals Ltd.", "A")}, &SecurityChanges{})
+	if len(m.Securities) != 2 || m.Lookup(ExchangeDSE, "GP") != nil || m.Lookup(ExchangeCSE, "GP") == nil {
+		t.Errorf("merge() = %d securities, want BXPHARMA and GP of the cse", len(m.Securities))
+	}
+}
+
+func Test_normalizeCompanyName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Grameenphone Ltd.", "GRAMEENPHONE"},
+		{"Grameen Phone Limited", "GRAMEENPHONE"},
+		{"The ACME Laboratories Ltd.", "ACMELABORATORIES"},
+		{"Food & Allied Co.", "FOODANDALLIED"},
+		{"LTD", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeCompanyName(tt.name); got != tt.want {
+				t.Errorf("normalizeCompanyName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadSecurityMaster(t *testing.T) {
+	for _, text := range []string{`{"securities": [{"name": "GP"}]}`, `{"securities": {}}`} {
+		if _, err := LoadSecurityMaster(strings.NewReader(text)); err == nil {
+			t.Errorf("LoadSecurityMaster(%s) error = nil", text)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package bdstockexchange
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// session holds the cookies the exchange website sets and the hidden fields of its forms, like a csrf token, so they
+// are sent back with the later requests the way a browser does. It is shared by the copies of a client and is safe
+// for concurrent use
+type session struct {
+	jar http.CookieJar
+
+	mu     sync.Mutex
+	fields map[string]url.Values
+}
+
+// newSession returns an empty session
+func newSession() *session {
+	// cookiejar.New never fails without options
+	jar, _ := cookiejar.New(nil)
+	return &session{jar: jar, fields: make(map[string]url.Values)}
+}
+
+// formFields returns the hidden fields of the form of the page or nil if the page was not fetched yet.
+// A nil session has no fields
+func (s *session) formFields(page string) url.Values {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fields[page]
+}
+
+// setFormFields keeps the hidden fields of the form of the page. Nil fields forget them. A nil session keeps nothing
+func (s *session) setFormFields(page string, fields url.Values) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if fields == nil {
+		delete(s.fields, page)
+		return
+	}
+	s.fields[page] = fields
+}
+
+// postForm submits the form of the page with the values and returns the parsed response. The page is fetched before
+// the first post for the session cookies and the hidden fields of its form, which are then posted with the values.
+// If the website rejects a post with a 403 or 419 status, ex: for an expired csrf token, the page is fetched again
+// and the post is sent once more
+func (cl *client) postForm(ctx context.Context, page string, values url.Values) (*html.Node, error) {
+	doc, err := cl.submitForm(ctx, page, values)
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusForbidden || statusErr.StatusCode == 419) {
+		cl.session.setFormFields(page, nil)
+		return cl.submitForm(ctx, page, values)
+	}
+	return doc, err
+}
+
+// submitForm posts the values with the hidden fields of the form of the page, fetching the page first if its fields
+// are not known yet
+func (cl *client) submitForm(ctx context.Context, page string, values url.Values) (*html.Node, error) {
+	fields := cl.session.formFields(page)
+	if fields == nil {
+		doc, err := cl.loadURL(ctx, page)
+		if err != nil {
+			return nil, err
+		}
+		if fields, err = readFormFields(doc, page, values); err != nil {
+			return nil, err
+		}
+		cl.session.setFormFields(page, fields)
+	}
+
+	form := make(url.Values, len(fields)+len(values))
+	for name, v := range fields {
+		form[name] = v
+	}
+	for name, v := range values {
+		form[name] = v
+	}
+	req, err := cl.newRequest(ctx, http.MethodPost, page, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Referer", page)
+	if u, err := url.Parse(page); err == nil {
+		req.Header.Set("Origin", u.Scheme+"://"+u.Host)
+	}
+	return cl.do(req)
+}
+
+// readFormFields returns the hidden fields of the form of the page with an input or a select named like one of the
+// values. It returns an error wrapping ErrLayoutChanged if the page has no such form
+func readFormFields(doc *html.Node, page string, values url.Values) (url.Values, error) {
+	for _, form := range htmlquery.Find(doc, "//form") {
+		found := false
+		for name := range values {
+			if htmlquery.FindOne(form, `//*[@name="`+name+`"]`) != nil {
+				found = true
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+
+		fields := url.Values{}
+		for _, input := range htmlquery.Find(form, `//input[@type="hidden"]`) {
+			if name := htmlquery.SelectAttr(input, "name"); name != "" {
+				fields.Add(name, htmlquery.SelectAttr(input, "value"))
+			}
+		}
+		return fields, nil
+	}
+	return nil, errLayoutChanged(page, "search form")
+}
@@ -0,0 +1,141 @@
+package bdstockexchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/antchfx/htmlquery"
+)
+
+// formServer is a website with a form protected by a session cookie and a csrf token, which changes with the generation
+type formServer struct {
+	generation int64
+	pages      int64
+	posts      int64
+	userAgent  atomic.Value
+}
+
+func (s *formServer) token() string {
+	return fmt.Sprintf("token-%d", atomic.LoadInt64(&s.generation))
+}
+
+func (s *formServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.userAgent.Store(r.UserAgent())
+	if r.Method == http.MethodGet {
+		atomic.AddInt64(&s.pages, 1)
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "1", Path: "/"})
+		fmt.Fprintf(w, `<form method="post"><input type="hidden" name="_token" value="%s"><select name="Year"></select></form>`, s.token())
+		return
+	}
+	atomic.AddInt64(&s.posts, 1)
+	if c, err := r.Cookie("session"); err != nil || c.Value != "1" || r.PostFormValue("_token") != s.token() {
+		http.Error(w, "Page Expired", 419)
+		return
+	}
+	fmt.Fprintf(w, `<p id="year">%s</p>`, r.PostFormValue("Year"))
+}
+
+func Test_client_postForm(t *testing.T) {
+	s := &formServer{}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	cl := newClient(ts.URL, nil)
+
+	post := func(year string) {
+		t.Helper()
+		doc, err := cl.postForm(context.Background(), cl.url(ts.URL, "/form"), url.Values{"Year": {year}})
+		if err != nil {
+			t.Fatalf("postForm() error = %v", err)
+		}
+		if got := htmlquery.InnerText(htmlquery.FindOne(doc, `//p[@id="year"]`)); got != year {
+			t.Errorf("postForm() = %q, want %q", got, year)
+		}
+	}
+
+	post("2020")
+	post("2019")
+	if pages, posts := atomic.LoadInt64(&s.pages), atomic.LoadInt64(&s.posts); pages != 1 || posts != 2 {
+		t.Errorf("postForm() fetched the form %d times for %d posts, want once for 2", pages, posts)
+	}
+	if ua := s.userAgent.Load(); ua != DefaultUserAgent {
+		t.Errorf("postForm() User-Agent = %v, want %v", ua, DefaultUserAgent)
+	}
+
+	// an expired token is fetched again
+	atomic.AddInt64(&s.generation, 1)
+	post("2018")
+	if pages, posts := atomic.LoadInt64(&s.pages), atomic.LoadInt64(&s.posts); pages != 2 || posts != 4 {
+		t.Errorf("postForm() with an expired token = %d pages and %d posts, want 2 and 4", pages, posts)
+	}
+}
+
+func Test_client_postForm_errors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/error":
+			http.Error(w, "error", http.StatusInternalServerError)
+		case r.URL.Path == "/rejected" && r.Method == http.MethodPost:
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		default:
+			fmt.Fprint(w, `<form method="post"><input type="date" name="pe_date"></form>`)
+		}
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		values  url.Values
+		wantErr error
+	}{
+		{"page error", "/error", url.Values{"pe_date": {"2020-10-15"}}, ErrHTTPStatus},
+		{"no form", "/form", url.Values{"Year": {"2020"}}, ErrLayoutChanged},
+		{"rejected twice", "/rejected", url.Values{"pe_date": {"2020-10-15"}}, ErrHTTPStatus},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl := newClient(ts.URL, []Option{WithUserAgent("test")})
+			if _, err := cl.postForm(context.Background(), ts.URL+tt.path, tt.values); !errors.Is(err, tt.wantErr) {
+				t.Errorf("postForm() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_readFormFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		page    string
+		want    url.Values
+		wantErr error
+	}{
+		{"hidden fields", `<form><input type="hidden" name="_token" value="abc"><input type="hidden" name="lang" value="en">` +
+			`<select name="Year"></select></form>`, url.Values{"_token": {"abc"}, "lang": {"en"}}, nil},
+		{"no hidden fields", `<form><select name="Year"></select></form>`, url.Values{}, nil},
+		{"other form", `<form><input type="hidden" name="_token" value="abc"><input name="q"></form>` +
+			`<form><select name="Year"></select></form>`, url.Values{}, nil},
+		{"no form", `<select name="Year"></select>`, nil, ErrLayoutChanged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := htmlquery.Parse(strings.NewReader(tt.page))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := readFormFields(doc, "weekly_report", url.Values{"Year": {"2020"}})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("readFormFields() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Encode() != tt.want.Encode() {
+				t.Errorf("readFormFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
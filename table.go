@@ -0,0 +1,112 @@
+package bdstockexchange
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// column is a table column needed by a parser with the header texts it is printed with on the exchange website
+type column struct {
+	name    string
+	headers []string
+}
+
+// table is a html table with its columns mapped by the header row
+type table struct {
+	columns map[string]int
+	rows    [][]*html.Node
+}
+
+// normalizeHeader returns the header text in upper case without the footnote stars and repeated spaces
+// ex : "LTP*" becomes "LTP" and "VALUE (mn)" becomes "VALUE (MN)"
+func normalizeHeader(text string) string {
+	text = strings.Replace(text, "*", "", -1)
+	return strings.ToUpper(strings.Join(strings.Fields(text), " "))
+}
+
+// findTable returns the first table under the node whose header row has all the columns.
+// It returns an error wrapping ErrLayoutChanged naming the missing column if there is no such table
+func findTable(node *html.Node, url string, columns []column) (*table, error) {
+	var missing string
+	for _, t := range htmlquery.Find(node, "//table") {
+		headers, rows := readTable(t)
+		if headers == nil {
+			continue
+		}
+		mapped, name := mapColumns(headers, columns)
+		if name != "" {
+			if missing == "" {
+				missing = name
+			}
+			continue
+		}
+		return &table{columns: mapped, rows: rows}, nil
+	}
+	if missing == "" {
+		return nil, errLayoutChanged(url, "table header")
+	}
+	return nil, errLayoutChanged(url, fmt.Sprintf("column %q", missing))
+}
+
+// readTable returns the header texts of the first row with th cells and the td cells of every other row
+func readTable(t *html.Node) ([]string, [][]*html.Node) {
+	var headers []string
+	rows := make([][]*html.Node, 0)
+	for _, tr := range htmlquery.Find(t, "//tr") {
+		if th := htmlquery.Find(tr, "th"); len(th) > 0 && headers == nil {
+			for _, h := range th {
+				headers = append(headers, normalizeHeader(htmlquery.InnerText(h)))
+			}
+			continue
+		}
+		if td := htmlquery.Find(tr, "td"); len(td) > 0 {
+			rows = append(rows, td)
+		}
+	}
+	return headers, rows
+}
+
+// mapColumns maps every column to the position of its header. It returns the name of the first column not found
+func mapColumns(headers []string, columns []column) (map[string]int, string) {
+	mapped := make(map[string]int, len(columns))
+	for _, c := range columns {
+		found := false
+		for i, h := range headers {
+			for _, alias := range c.headers {
+				if h == normalizeHeader(alias) {
+					found = true
+					break
+				}
+			}
+			if found {
+				mapped[c.name] = i
+				break
+			}
+		}
+		if !found {
+			return nil, c.name
+		}
+	}
+	return mapped, ""
+}
+
+// text returns the trimmed text of the column in the row
+func (t *table) text(row []*html.Node, name string) string {
+	i, ok := t.columns[name]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(htmlquery.InnerText(row[i]))
+}
+
+// cell returns the trimmed text of the column in the cells of a pdf row
+func (t *table) cell(cells []string, name string) string {
+	i, ok := t.columns[name]
+	if !ok || i >= len(cells) {
+		return ""
+	}
+	return strings.TrimSpace(cells[i])
+}
@@ -0,0 +1,90 @@
+package bdstockexchange
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/antchfx/htmlquery"
+)
+
+func Test_normalizeHeader(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"", "LTP*", "LTP"},
+		{"", " VALUE  (mn) ", "VALUE (MN)"},
+		{"", "Trading\n\t\tCode", "TRADING CODE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeHeader(tt.text); got != tt.want {
+				t.Errorf("normalizeHeader() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_findTable(t *testing.T) {
+	columns := []column{
+		{"trading_code", []string{"TRADING CODE"}},
+		{"ltp", []string{"LTP*"}},
+		{"value", []string{"VALUE (mn)"}},
+	}
+	tests := []struct {
+		name    string
+		html    string
+		want    map[string]string
+		wantErr error
+	}{
+		{
+			"reordered",
+			`<table><tr><th>VALUE (mn)</th><th>LTP*</th><th>TRADING CODE</th></tr><tr><td>1.5</td><td>20</td><td>GP</td></tr></table>`,
+			map[string]string{"trading_code": "GP", "ltp": "20", "value": "1.5"},
+			nil,
+		},
+		{
+			"second table",
+			`<table><tr><th>Menu</th></tr></table><table><thead><tr><th>Trading Code</th><th>LTP</th><th>Value (MN)</th></tr></thead><tbody><tr><td> GP </td><td>20</td><td>1.5</td></tr></tbody></table>`,
+			map[string]string{"trading_code": "GP", "ltp": "20", "value": "1.5"},
+			nil,
+		},
+		{
+			"missing column",
+			`<table><tr><th>TRADING CODE</th><th>LTP*</th></tr><tr><td>GP</td><td>20</td></tr></table>`,
+			nil,
+			ErrLayoutChanged,
+		},
+		{
+			"no table",
+			`<div>maintenance</div>`,
+			nil,
+			ErrLayoutChanged,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := htmlquery.Parse(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := findTable(doc, "https://www.dsebd.org/", columns)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("findTable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(got.rows) != 1 {
+				t.Fatalf("findTable() rows = %d, want 1", len(got.rows))
+			}
+			for name, want := range tt.want {
+				if text := got.text(got.rows[0], name); text != want {
+					t.Errorf("table.text(%q) = %q, want %q", name, text, want)
+				}
+			}
+		})
+	}
+}
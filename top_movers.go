@@ -0,0 +1,136 @@
+package bdstockexchange
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// dseMoverTables are the headings of the top mover tables of the dse home page by kind
+var dseMoverTables = map[moverKind][]string{
+	TopGainers:  {"Top Ten Gainer", "Top 10 Gainers"},
+	TopLosers:   {"Top Ten Loser", "Top 10 Losers"},
+	TopByValue:  {"Top Twenty Shares by Value", "Top 20 Shares by Value"},
+	TopByVolume: {"Top Twenty Shares by Volume", "Top 20 Shares by Volume"},
+	TopByTrade:  {"Top Twenty Shares by Trade", "Top 20 Shares by Trade"},
+}
+
+// GetTopMovers returns the first n shares of the top mover table of the kind from the dse home page, ex: TopGainers.
+// The dse lists ten gainers and losers and twenty shares by value, volume and trade, so at most as many are returned.
+// Use TopMovers on the latest prices for more shares or if the list of the dse lags.
+// It returns an error wrapping ErrInvalidArgument for an unknown kind or a n less than 1
+func (d *DSE) GetTopMovers(ctx context.Context, kind moverKind, n int) ([]*LatestPricesWithPercentage, error) {
+	headings, ok := dseMoverTables[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown top mover kind %d", ErrInvalidArgument, kind)
+	}
+	if n < 1 {
+		return nil, fmt.Errorf("%w: number of top movers %d is less than 1", ErrInvalidArgument, n)
+	}
+
+	url := d.url("/")
+	doc, err := d.client.loadURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	t, err := findTableUnder(doc, url, headings, dsePercentageColumns)
+	if err != nil {
+		return nil, err
+	}
+	shares, err := readPercentageRows(ctx, t, url)
+	if err != nil {
+		return nil, err
+	}
+	if len(shares) > n {
+		shares = shares[:n]
+	}
+	return shares, nil
+}
+
+// TopMovers returns the first n shares of the kind computed from the shares the way the dse lists them, ex: the top
+// gainers are the shares with a rise of the last trade price from yesterday's closing price sorted by the rise in
+// percent. The change in percent is rounded to 2 decimals before the shares are ranked, so a rise of 5.004% ties with
+// one of 5.001% and a change which rounds to 0 is neither a gain nor a loss. Shares with the same value are sorted by
+// trading code and shares which were not traded are left out of the tables by value, volume and trade.
+// It returns an error wrapping ErrInvalidArgument for an unknown kind or a n less than 1
+func TopMovers(shares []*DSEShare, kind moverKind, n int) ([]*LatestPricesWithPercentage, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("%w: number of top movers %d is less than 1", ErrInvalidArgument, n)
+	}
+	// rank returns the value a share is ranked by in descending order. A share of a rank of 0 or less is left out
+	var rank func(m *LatestPricesWithPercentage) float64
+	switch kind {
+	case TopGainers:
+		rank = func(m *LatestPricesWithPercentage) float64 { return m.PercentageChange }
+	case TopLosers:
+		rank = func(m *LatestPricesWithPercentage) float64 { return -m.PercentageChange }
+	case TopByValue:
+		rank = func(m *LatestPricesWithPercentage) float64 { return m.ValueInMN.Float64() }
+	case TopByVolume:
+		rank = func(m *LatestPricesWithPercentage) float64 { return float64(m.Volume) }
+	case TopByTrade:
+		rank = func(m *LatestPricesWithPercentage) float64 { return float64(m.Trade) }
+	default:
+		return nil, fmt.Errorf("%w: unknown top mover kind %d", ErrInvalidArgument, kind)
+	}
+
+	movers := make([]*LatestPricesWithPercentage, 0, len(shares))
+	for _, s := range shares {
+		m := &LatestPricesWithPercentage{
+			TradingCode:      s.TradingCode,
+			LTP:              s.LTP,
+			High:             s.High,
+			Low:              s.Low,
+			CloseP:           s.CloseP,
+			YCP:              s.YCP,
+			PercentageChange: math.Round(percentageChange(s.LTP.Float64(), s.YCP.Float64())*100) / 100,
+			Trade:            s.Trade,
+			ValueInMN:        s.ValueInMN,
+			Volume:           s.Volume,
+		}
+		if rank(m) > 0 {
+			movers = append(movers, m)
+		}
+	}
+	sort.SliceStable(movers, func(i, j int) bool {
+		if ri, rj := rank(movers[i]), rank(movers[j]); ri != rj {
+			return ri > rj
+		}
+		return movers[i].TradingCode < movers[j].TradingCode
+	})
+	if len(movers) > n {
+		movers = movers[:n]
+	}
+	for i, m := range movers {
+		m.ID = i + 1
+	}
+	return movers, nil
+}
+
+// findTableUnder returns the first table after the h2 heading printed as one of the headings, with the columns.
+// It returns an error wrapping ErrLayoutChanged if there is no such heading or table
+func findTableUnder(doc *html.Node, url string, headings []string, columns []column) (*table, error) {
+	for _, h := range htmlquery.Find(doc, "//h2") {
+		text := normalizeHeader(htmlquery.InnerText(h))
+		for _, heading := range headings {
+			if text != normalizeHeader(heading) {
+				continue
+			}
+			t := htmlquery.FindOne(h, "following::table[1]")
+			if t == nil {
+				return nil, errLayoutChanged(url, fmt.Sprintf("%q table", heading))
+			}
+			headers, rows := readTable(t)
+			mapped, missing := mapColumns(headers, columns)
+			if missing != "" {
+				return nil, errLayoutChanged(url, fmt.Sprintf("column %q of the %q table", missing, heading))
+			}
+			return &table{columns: mapped, rows: rows}, nil
+		}
+	}
+	return nil, errLayoutChanged(url, fmt.Sprintf("%q heading", headings[0]))
+}
@@ -0,0 +1,126 @@
+package bdstockexchange
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/antchfx/htmlquery"
+)
+
+func TestDSE_GetTopMovers(t *testing.T) {
+	tests := []struct {
+		name   string
+		kind   moverKind
+		golden string
+	}{
+		{"gainers", TopGainers, "dse_top_gainers"},
+		{"losers", TopLosers, "dse_top_losers"},
+		{"by value", TopByValue, "dse_top_by_value"},
+		{"by volume", TopByVolume, "dse_top_by_volume"},
+		{"by trade", TopByTrade, "dse_top_by_trade"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testDSE().GetTopMovers(context.Background(), tt.kind, 20)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertGolden(t, tt.golden, got)
+		})
+	}
+
+	got, err := testDSE().GetTopMovers(context.Background(), TopGainers, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].TradingCode != "BEXIMCO" || got[1].TradingCode != "ACI" {
+		t.Errorf("GetTopMovers() of 2 gainers = %+v, want BEXIMCO and ACI", got)
+	}
+
+	for _, args := range []struct {
+		kind moverKind
+		n    int
+	}{{TopGainers, 0}, {TopByTrade + 1, 10}} {
+		if _, err := testDSE().GetTopMovers(context.Background(), args.kind, args.n); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("GetTopMovers(%d, %d) error = %v, want ErrInvalidArgument", args.kind, args.n, err)
+		}
+	}
+}
+
+func TestTopMovers(t *testing.T) {
+	shares := []*DSEShare{
+		{TradingCode: "A", LTP: MustParseDecimal("11"), YCP: MustParseDecimal("10"), Trade: 5, ValueInMN: MustParseDecimal("1.5"), Volume: 100},
+		{TradingCode: "B", LTP: MustParseDecimal("9"), YCP: MustParseDecimal("10"), Trade: 9, ValueInMN: MustParseDecimal("0.5"), Volume: 300},
+		{TradingCode: "C", LTP: MustParseDecimal("22"), YCP: MustParseDecimal("20"), Trade: 5, ValueInMN: MustParseDecimal("2.5"), Volume: 100},
+		{TradingCode: "D", LTP: MustParseDecimal("30"), YCP: MustParseDecimal("30")},
+		{TradingCode: "E", LTP: MustParseDecimal("8"), YCP: MustParseDecimal("10"), Trade: 1, ValueInMN: MustParseDecimal("0.1"), Volume: 10},
+	}
+	codes := func(movers []*LatestPricesWithPercentage) string {
+		var c []string
+		for i, m := range movers {
+			if m.ID != i+1 {
+				t.Errorf("TopMovers() ID of %s = %d, want %d", m.TradingCode, m.ID, i+1)
+			}
+			c = append(c, m.TradingCode)
+		}
+		return strings.Join(c, ",")
+	}
+
+	tests := []struct {
+		name    string
+		kind    moverKind
+		n       int
+		want    string
+		wantErr error
+	}{
+		{"gainers with a tie", TopGainers, 10, "A,C", nil},
+		{"losers", TopLosers, 10, "E,B", nil},
+		{"by value", TopByValue, 10, "C,A,B,E", nil},
+		{"by volume with a tie", TopByVolume, 3, "B,A,C", nil},
+		{"by trade", TopByTrade, 1, "B", nil},
+		{"zero", TopGainers, 0, "", ErrInvalidArgument},
+		{"unknown kind", TopByTrade + 1, 10, "", ErrInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TopMovers(shares, tt.kind, tt.n)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("TopMovers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if c := codes(got); c != tt.want {
+				t.Errorf("TopMovers() = %s, want %s", c, tt.want)
+			}
+		})
+	}
+
+	got, _ := TopMovers(shares, TopLosers, 1)
+	if got[0].PercentageChange != -20 {
+		t.Errorf("TopMovers() PercentageChange = %v, want -20", got[0].PercentageChange)
+	}
+
+	// 5.004% and 5.001% are both listed as 5.00%, so they tie and 0.004% is not a gain
+	rounded := []*DSEShare{
+		{TradingCode: "G", LTP: MustParseDecimal("1050.04"), YCP: MustParseDecimal("1000")},
+		{TradingCode: "F", LTP: MustParseDecimal("1050.01"), YCP: MustParseDecimal("1000")},
+		{TradingCode: "H", LTP: MustParseDecimal("1000.04"), YCP: MustParseDecimal("1000")},
+	}
+	got, _ = TopMovers(rounded, TopGainers, 10)
+	if c := codes(got); c != "F,G" || got[0].PercentageChange != 5 || got[1].PercentageChange != 5 {
+		t.Errorf("TopMovers() of rounded gainers = %s %+v, want F,G of 5%%", c, got)
+	}
+}
+
+func Test_findTableUnder(t *testing.T) {
+	doc, err := htmlquery.Parse(strings.NewReader(`<h2>Top Ten Gainer</h2><p>no table</p>` +
+		`<h2>Top Ten Loser</h2><table><tr><th>#</th><th>TRADING CODE</th></tr></table>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, headings := range [][]string{dseMoverTables[TopGainers], dseMoverTables[TopLosers], dseMoverTables[TopByValue]} {
+		if _, err := findTableUnder(doc, "index", headings, dsePercentageColumns); !errors.Is(err, ErrLayoutChanged) {
+			t.Errorf("findTableUnder(%q) error = %v, want ErrLayoutChanged", headings[0], err)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package bdstockexchange
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// fixtureContentTypes are the content types of the downloads which are saved with their own extension. Every other
+// response is saved and replayed as html
+var fixtureContentTypes = map[string]string{
+	".pdf": "application/pdf",
+}
+
+// NewRecordingTransport returns a http.RoundTripper which sends the requests with base and saves every successful
+// response body under dir, so it can be replayed later with NewReplayTransport. http.DefaultTransport is used if base is nil
+func NewRecordingTransport(dir string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &recordingTransport{dir: dir, base: base}
+}
+
+// NewReplayTransport returns a http.RoundTripper which answers the requests with the responses saved under dir by
+// a recording transport. It never touches the network and fails the request if no response was recorded for it
+func NewReplayTransport(dir string) http.RoundTripper {
+	return &replayTransport{dir: dir}
+}
+
+type recordingTransport struct {
+	dir  string
+	base http.RoundTripper
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path, err := fixturePath(t.dir, req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(path, body, 0644); err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+type replayTransport struct {
+	dir string
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path, err := fixturePath(t.dir, req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no recorded response for %s %s: %v", req.Method, req.URL, err)
+	}
+	contentType, ok := fixtureContentTypes[filepath.Ext(path)]
+	if !ok {
+		contentType = "text/html; charset=utf-8"
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{contentType}},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// fixturePath returns the file a response for the request is saved to. It is built from the host, the path and the
+// query of the url, ex: www.dsebd.org/latest_share_price_all_group.php_group=A.html. The hash of the body is appended
+// for requests with a body, so every form post is saved separately. The request body is restored after hashing.
+// A download of fixtureContentTypes keeps its extension, ex: www.cse.com.bd/assets/weekly_report/report.pdf
+func fixturePath(dir string, req *http.Request) (string, error) {
+	name := strings.TrimPrefix(req.URL.Path, "/")
+	if name == "" || strings.HasSuffix(name, "/") {
+		name += "index"
+	}
+	if req.URL.RawQuery != "" {
+		name += "_" + sanitizeFixtureName(req.URL.RawQuery)
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return "", err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		if len(body) > 0 {
+			sum := sha1.Sum(body)
+			name += "@" + hex.EncodeToString(sum[:])[:12]
+		}
+	}
+	ext := strings.ToLower(path.Ext(req.URL.Path))
+	if _, ok := fixtureContentTypes[ext]; !ok {
+		ext = ".html"
+	}
+	if ext == ".html" || !strings.HasSuffix(name, ext) {
+		name += ext
+	}
+	return filepath.Join(dir, strings.ToLower(req.URL.Host), filepath.FromSlash(name)), nil
+}
+
+// sanitizeFixtureName replaces the characters which are not safe in a file name
+func sanitizeFixtureName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_', r == '=':
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -0,0 +1,147 @@
+package bdstockexchange
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var (
+	update      = flag.Bool("update", false, "update the golden files in testdata/golden")
+	recordPages = flag.Bool("record", false, "record the exchange pages in testdata from the live websites")
+)
+
+// testTransport returns the transport the tests fetch the exchange pages with.
+// It replays the pages in testdata, or records them from the live websites with the -record flag
+func testTransport() http.RoundTripper {
+	if *recordPages {
+		return NewRecordingTransport("testdata", nil)
+	}
+	return NewReplayTransport("testdata")
+}
+
+// testDSE returns a DSE fetching the pages in testdata
+func testDSE() *DSE {
+	return NewDSE(WithHTTPClient(&http.Client{Transport: testTransport()}))
+}
+
+// testCSE returns a CSE fetching the pages in testdata
+func testCSE() *CSE {
+	return NewCSE(WithHTTPClient(&http.Client{Transport: testTransport()}))
+}
+
+// assertGolden compares the json of got with testdata/golden/<name>.json. The file is rewritten with the -update flag
+func assertGolden(t *testing.T, name string, got interface{}) {
+	t.Helper()
+	b, err := json.MarshalIndent(got, "", "\t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b = append(b, '\n')
+	path := filepath.Join("testdata", "golden", name+".json")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, b, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("missing golden file, run the test with -update: %v", err)
+	}
+	if !bytes.Equal(b, want) {
+		t.Errorf("%s does not match the golden file %s\ngot:\n%s\nwant:\n%s", name, path, b, want)
+	}
+}
+
+func Test_fixturePath(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		body   string
+		want   string
+	}{
+		{"index", http.MethodGet, "https://www.dsebd.org/", "", filepath.Join("testdata", "www.dsebd.org", "index.html")},
+		{"query", http.MethodGet, "https://www.dsebd.org/latest_share_price_all_group.php?group=A", "", filepath.Join("testdata", "www.dsebd.org", "latest_share_price_all_group.php_group=A.html")},
+		{"nested", http.MethodGet, "https://www.CSE.com.bd/market/current_price", "", filepath.Join("testdata", "www.cse.com.bd", "market", "current_price.html")},
+		{"pdf", http.MethodGet, "https://www.cse.com.bd/assets/weekly_report/weekly_report_20201015.pdf", "", filepath.Join("testdata", "www.cse.com.bd", "assets", "weekly_report", "weekly_report_20201015.pdf")},
+		{"pdf query", http.MethodGet, "https://www.cse.com.bd/assets/report.pdf?v=2", "", filepath.Join("testdata", "www.cse.com.bd", "assets", "report.pdf_v=2.pdf")},
+		{"form", http.MethodPost, "https://www.cse.com.bd/market/weekly_report", "Year=2020", filepath.Join("testdata", "www.cse.com.bd", "market", "weekly_report@0be8fe4cbd1c.html")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			got, err := fixturePath("testdata", req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("fixturePath() = %v, want %v", got, tt.want)
+			}
+			if body, _ := ioutil.ReadAll(req.Body); string(body) != tt.body {
+				t.Errorf("fixturePath() left request body %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestRecordingTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("<html>" + r.URL.Path + "</html>"))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "bdstockexchange")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	recorder := &http.Client{Transport: NewRecordingTransport(dir, nil)}
+	for _, path := range []string{"/market/current_price", "/assets/report.pdf", "/missing"} {
+		resp, err := recorder.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	replayer := &http.Client{Transport: NewReplayTransport(dir)}
+	resp, err := replayer.Get(ts.URL + "/market/current_price")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "<html>/market/current_price</html>" {
+		t.Errorf("replayed body = %q", body)
+	}
+	for path, want := range map[string]string{"/market/current_price": "text/html; charset=utf-8", "/assets/report.pdf": "application/pdf"} {
+		resp, err := replayer.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if got := resp.Header.Get("Content-Type"); got != want {
+			t.Errorf("replayed Content-Type of %s = %q, want %q", path, got, want)
+		}
+	}
+	if _, err := replayer.Get(ts.URL + "/missing"); err == nil {
+		t.Error("replaying a not recorded request succeeded, want an error")
+	}
+}
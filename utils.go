@@ -1,11 +1,36 @@
 package bdstockexchange
 
 import (
-	"log"
+	"fmt"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
 )
 
+// Dhaka is the Asia/Dhaka location the dates and times of the exchanges are parsed in.
+// It is a fixed +06:00 zone if the time zone database is not installed
+var Dhaka = loadDhaka()
+
+// loadDhaka returns the Asia/Dhaka location or a fixed +06:00 zone if it can not be loaded
+func loadDhaka() *time.Location {
+	if loc, err := time.LoadLocation("Asia/Dhaka"); err == nil {
+		return loc
+	}
+	return time.FixedZone("+06", 6*60*60)
+}
+
+// dateLayouts are the layouts the exchange websites print the dates with
+var dateLayouts = []string{"2006-01-02", "02-01-2006", "02/01/2006", "02 Jan 2006", "Jan 2, 2006", "January 2, 2006"}
+
+// dhakaDate returns the midnight in the Dhaka location of the day of t
+func dhakaDate(t time.Time) time.Time {
+	y, m, d := t.In(Dhaka).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, Dhaka)
+}
+
 // isValidCategoryName checks if the user input catergory is valid
 func isValidCategoryName(categoryName string) bool {
 	if categoryName == "A" || categoryName == "B" || categoryName == "G" || categoryName == "N" || categoryName == "Z" {
@@ -20,37 +45,126 @@ func normalizeAmerican(old string) string {
 }
 
 // toFloat64 returns the float64 cleaning the input string
-func toFloat64(text string) float64 {
-	if text == "--" {
-		text = strings.Replace(text, "--", "0", -1)
-	}
-	if text == "N/A" {
-		text = strings.Replace(text, "N/A", "0", -1)
+func toFloat64(text string) (float64, error) {
+	text = strings.TrimSpace(text)
+	if text == "--" || text == "N/A" || text == "-" {
+		return 0, nil
 	}
-	val, err := strconv.ParseFloat(normalizeAmerican(text), 64)
-	if err != nil {
-		log.Fatal(err)
+	return strconv.ParseFloat(normalizeAmerican(text), 64)
+}
+
+// toDecimal returns the exact Decimal cleaning the input string
+func toDecimal(text string) (Decimal, error) {
+	text = strings.TrimSpace(text)
+	if text == "--" || text == "N/A" || text == "-" {
+		return Decimal{}, nil
 	}
-	return val
+	return ParseDecimal(text)
 }
 
 // toInt64 returns the int64 cleaning the input string
-func toInt64(text string) int64 {
+func toInt64(text string) (int64, error) {
 	if strings.Contains(text, " ") {
 		text = strings.Replace(text, " ", "", -1)
 	}
-	val, err := strconv.ParseInt(normalizeAmerican(text), 10, 64)
+	text = strings.TrimSpace(text)
+	if text == "--" || text == "N/A" || text == "-" {
+		return 0, nil
+	}
+	return strconv.ParseInt(normalizeAmerican(text), 10, 64)
+}
+
+// toTime parses the text in the Dhaka location with the first of the layouts it matches.
+// It returns the zero time for an empty text or a text without value like "-" or "N/A"
+func toTime(text string, layouts ...string) (time.Time, error) {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" || text == "--" || text == "N/A" || text == "-" {
+		return time.Time{}, nil
+	}
+	var err error
+	for _, layout := range layouts {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, text, Dhaka); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// toInt parse the int from a input string
+func toInt(text string) (int, error) {
+	return strconv.Atoi(strings.TrimSpace(text))
+}
+
+// cellParser parses the cells of a page table. It keeps the first error as a ParseError with the position of the cell,
+// so a row can be parsed field by field and checked once
+type cellParser struct {
+	url string
+	row int
+	err error
+}
+
+// fail records the first parse error
+func (p *cellParser) fail(column, text string, err error) {
+	if p.err == nil {
+		p.err = &ParseError{URL: p.url, Row: p.row, Column: column, Text: text, Err: err}
+	}
+}
+
+// find returns the inner text of the first node of the expr under the node of the row. A missing node records an
+// error wrapping ErrLayoutChanged and returns an empty text, so a page which lost a cell fails instead of panicking
+func (p *cellParser) find(node *html.Node, column, expr string) string {
+	n := htmlquery.FindOne(node, expr)
+	if n == nil {
+		if p.err == nil {
+			p.err = errLayoutChanged(p.url, fmt.Sprintf("%s of row %d", column, p.row))
+		}
+		return ""
+	}
+	return htmlquery.InnerText(n)
+}
+
+// float64 parses the text of the column as float64
+func (p *cellParser) float64(column, text string) float64 {
+	val, err := toFloat64(text)
 	if err != nil {
-		log.Println(err)
+		p.fail(column, text, err)
 	}
 	return val
 }
 
-// toInt parse the int from a input string
-func toInt(text string) int {
-	val, err := strconv.Atoi(text)
+// decimal parses the text of the column as an exact Decimal
+func (p *cellParser) decimal(column, text string) Decimal {
+	val, err := toDecimal(text)
+	if err != nil {
+		p.fail(column, text, err)
+	}
+	return val
+}
+
+// int64 parses the text of the column as int64
+func (p *cellParser) int64(column, text string) int64 {
+	val, err := toInt64(text)
+	if err != nil {
+		p.fail(column, text, err)
+	}
+	return val
+}
+
+// int parses the text of the column as int
+func (p *cellParser) int(column, text string) int {
+	val, err := toInt(text)
+	if err != nil {
+		p.fail(column, text, err)
+	}
+	return val
+}
+
+// date parses the text of the column as a time in the Dhaka location with the layouts
+func (p *cellParser) date(column, text string, layouts ...string) time.Time {
+	val, err := toTime(text, layouts...)
 	if err != nil {
-		log.Fatal(err)
+		p.fail(column, text, err)
 	}
 	return val
 }
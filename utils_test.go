@@ -1,7 +1,10 @@
 package bdstockexchange
 
 import (
+	"errors"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func Test_isValidCategoryName(t *testing.T) {
@@ -53,17 +56,24 @@ func Test_toFloat64(t *testing.T) {
 		text string
 	}
 	tests := []struct {
-		name string
-		args args
-		want float64
+		name    string
+		args    args
+		want    float64
+		wantErr bool
 	}{
-		// TODO: Add test cases.
-		{"", args{text: "20,000.78"}, 20000.78},
-		{"", args{text: "50.07"}, 50.07},
+		{"", args{text: "20,000.78"}, 20000.78, false},
+		{"", args{text: "50.07"}, 50.07, false},
+		{"", args{text: "--"}, 0, false},
+		{"", args{text: "n/a"}, 0, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := toFloat64(tt.args.text); got != tt.want {
+			got, err := toFloat64(tt.args.text)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("toFloat64() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
 				t.Errorf("toFloat64() = %v, want %v", got, tt.want)
 			}
 		})
@@ -75,17 +85,23 @@ func Test_toInt64(t *testing.T) {
 		text string
 	}
 	tests := []struct {
-		name string
-		args args
-		want int64
+		name    string
+		args    args
+		want    int64
+		wantErr bool
 	}{
-		// TODO: Add test cases.
-		{"", args{text: "50,000"}, 50000},
-		{"", args{text: "40,000"}, 40000},
+		{"", args{text: "50,000"}, 50000, false},
+		{"", args{text: "40,000"}, 40000, false},
+		{"", args{text: "4.5"}, 0, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := toInt64(tt.args.text); got != tt.want {
+			got, err := toInt64(tt.args.text)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("toInt64() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
 				t.Errorf("toInt64() = %v, want %v", got, tt.want)
 			}
 		})
@@ -97,19 +113,73 @@ func Test_toInt(t *testing.T) {
 		text string
 	}
 	tests := []struct {
-		name string
-		args args
-		want int
+		name    string
+		args    args
+		want    int
+		wantErr bool
 	}{
-		// TODO: Add test cases.
-		{"", args{text: "1"}, 1},
-		{"", args{text: "2"}, 2},
+		{"", args{text: "1"}, 1, false},
+		{"", args{text: " 2 "}, 2, false},
+		{"", args{text: "two"}, 0, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := toInt(tt.args.text); got != tt.want {
+			got, err := toInt(tt.args.text)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("toInt() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
 				t.Errorf("toInt() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func Test_toTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		layouts []string
+		want    time.Time
+		wantErr bool
+	}{
+		{"iso date", "2020-10-15", dateLayouts, time.Date(2020, 10, 15, 0, 0, 0, 0, Dhaka), false},
+		{"dse date", " Oct  5, 2020 ", dateLayouts, time.Date(2020, 10, 5, 0, 0, 0, 0, Dhaka), false},
+		{"day first", "15-10-2020", dateLayouts, time.Date(2020, 10, 15, 0, 0, 0, 0, Dhaka), false},
+		{"last update", "Oct 15, 2020 3:10 PM", []string{dseLastUpdateLayout}, time.Date(2020, 10, 15, 15, 10, 0, 0, Dhaka), false},
+		{"empty", "", dateLayouts, time.Time{}, false},
+		{"no value", "N/A", dateLayouts, time.Time{}, false},
+		{"invalid", "15th October", dateLayouts, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toTime(tt.text, tt.layouts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("toTime() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("toTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_cellParser(t *testing.T) {
+	p := &cellParser{url: "https://www.dsebd.org/", row: 3}
+	if got := p.float64("ltp", "12.5"); got != 12.5 {
+		t.Errorf("cellParser.float64() = %v, want %v", got, 12.5)
+	}
+	p.int64("volume", "1,2x")
+	p.int("id", "bad")
+
+	var parseErr *ParseError
+	if !errors.As(p.err, &parseErr) {
+		t.Fatalf("cellParser.err = %v, want a *ParseError", p.err)
+	}
+	want := &ParseError{URL: "https://www.dsebd.org/", Row: 3, Column: "volume", Text: "1,2x", Err: parseErr.Err}
+	if !reflect.DeepEqual(parseErr, want) {
+		t.Errorf("cellParser.err = %v, want %v", parseErr, want)
+	}
+}
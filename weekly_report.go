@@ -0,0 +1,326 @@
+package bdstockexchange
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// weeklyIndexColumns are the columns of the index movement table of the cse weekly report
+var weeklyIndexColumns = []column{
+	{"index", []string{"Index", "Indices"}},
+	{"this_week", []string{"This Week"}},
+	{"last_week", []string{"Last Week"}},
+	{"change", []string{"Change"}},
+	{"change_percentage", []string{"Change %", "% Change"}},
+}
+
+// weeklyTurnoverColumns are the columns of the market turnover table of the cse weekly report
+var weeklyTurnoverColumns = []column{
+	{"particulars", []string{"Particulars"}},
+	{"this_week", []string{"This Week"}},
+	{"last_week", []string{"Last Week"}},
+}
+
+// weeklyMoverColumns are the columns of the top gainers and top losers tables of the cse weekly report
+var weeklyMoverColumns = []column{
+	{"trading_code", []string{"Trading Code", "Company"}},
+	{"close_price", []string{"This Week Close", "Close Price"}},
+	{"last_week_close_price", []string{"Last Week Close"}},
+	{"change_percentage", []string{"Change %", "% Change"}},
+}
+
+// weeklyTurnoverShareColumns are the columns of the top turnover table of the cse weekly report
+var weeklyTurnoverShareColumns = []column{
+	{"trading_code", []string{"Trading Code", "Company"}},
+	{"volume", []string{"Volume"}},
+	{"value", []string{"Value (Tk. mn)", "Value (mn)", "Turnover (Tk. mn)"}},
+}
+
+// WeeklyStatistics holds the statistics of a cse weekly report
+type WeeklyStatistics struct {
+	Indices  []*WeeklyIndexMovement
+	ThisWeek WeeklyTotals
+	LastWeek WeeklyTotals
+	// TopGainers and TopLosers are the shares with the largest change of the close price over the week
+	TopGainers []*WeeklyMover
+	TopLosers  []*WeeklyMover
+	// TopTurnover are the shares traded with the largest value over the week
+	TopTurnover []*WeeklyTurnover
+}
+
+// WeeklyIndexMovement holds the movement of an index over the week
+type WeeklyIndexMovement struct {
+	Name             string
+	ThisWeek         float64
+	LastWeek         float64
+	Change           float64
+	ChangePercentage float64
+}
+
+// WeeklyTotals holds the totals of the trades of a week
+type WeeklyTotals struct {
+	Trade         int64
+	Volume        int64
+	ValueInMN     Decimal
+	MarketCapInMN Decimal
+}
+
+// WeeklyMover holds the weekly change of the close price of a share
+type WeeklyMover struct {
+	TradingCode        string
+	ClosePrice         Decimal
+	LastWeekClosePrice Decimal
+	ChangePercentage   float64
+}
+
+// WeeklyTurnover holds the weekly trades of a share
+type WeeklyTurnover struct {
+	TradingCode string
+	Volume      int64
+	ValueInMN   Decimal
+}
+
+// weeklySection is a table of the weekly report with the headings it is printed under
+type weeklySection struct {
+	name     string
+	headings []string
+	columns  []column
+	parse    func(p *cellParser, t *table, cells []string, s *WeeklyStatistics)
+}
+
+// weeklySections are the tables read from the cse weekly report. The headings and the columns follow the synthetic
+// report of testdata and are not checked against a recorded report yet, so record one with -record to confirm them
+var weeklySections = []*weeklySection{
+	{"index movement", []string{"Index Movement", "Indices"}, weeklyIndexColumns, parseWeeklyIndex},
+	{"market turnover", []string{"Market Turnover", "Turnover"}, weeklyTurnoverColumns, parseWeeklyTotals},
+	{"top gainers", []string{"Top 10 Gainers", "Top Gainers"}, weeklyMoverColumns, func(p *cellParser, t *table, cells []string, s *WeeklyStatistics) {
+		s.TopGainers = append(s.TopGainers, parseWeeklyMover(p, t, cells))
+	}},
+	{"top losers", []string{"Top 10 Losers", "Top Losers"}, weeklyMoverColumns, func(p *cellParser, t *table, cells []string, s *WeeklyStatistics) {
+		s.TopLosers = append(s.TopLosers, parseWeeklyMover(p, t, cells))
+	}},
+	{"top turnover", []string{"Top 10 Turnover", "Top Turnover"}, weeklyTurnoverShareColumns, parseWeeklyTurnover},
+}
+
+// GetWeeklyReport downloads the weekly report pdf of the link, ex: the ReportPDFLink of a report of GetAllWeeklyReports,
+// and returns the statistics printed in it. The pdf is read from the cache directory if one is set with WithCacheDir
+// and it was downloaded before
+func (c *CSE) GetWeeklyReport(link string) (*WeeklyStatistics, error) {
+	return c.GetWeeklyReportContext(context.Background(), link)
+}
+
+// GetWeeklyReportContext is like GetWeeklyReport but takes a context to cancel the request
+func (c *CSE) GetWeeklyReportContext(ctx context.Context, link string) (*WeeklyStatistics, error) {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || path.Base(u.Path) == "." || path.Base(u.Path) == "/" {
+		return nil, fmt.Errorf("%w: weekly report link %q is not a file", ErrInvalidArgument, link)
+	}
+	if !u.IsAbs() {
+		abs, _ := url.Parse(c.url(path.Join("/", u.Path)))
+		abs.RawQuery = u.RawQuery
+		u = abs
+	}
+
+	b, err := c.client.download(ctx, u.String(), cacheKey(u), "%PDF")
+	if err != nil {
+		return nil, err
+	}
+	return readWeeklyReport(bytes.NewReader(b), int64(len(b)), u.String())
+}
+
+// ParseWeeklyReport returns the statistics printed in a cse weekly report pdf which was downloaded before
+func ParseWeeklyReport(r io.Reader) (*WeeklyStatistics, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return readWeeklyReport(bytes.NewReader(b), int64(len(b)), "weekly report")
+}
+
+// readWeeklyReport reads the tables of the weekly report pdf. Every table starts after a heading, a row of a single
+// cell, with a header row and ends at the next row of a single cell
+func readWeeklyReport(r io.ReaderAt, size int64, url string) (*WeeklyStatistics, error) {
+	rows, err := readPDFRows(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s is not a readable pdf: %v", ErrLayoutChanged, url, err)
+	}
+
+	stats := &WeeklyStatistics{}
+	p := &cellParser{url: url}
+	found := make(map[*weeklySection]bool, len(weeklySections))
+	var section *weeklySection
+	var t *table
+	for i, cells := range rows {
+		p.row = i
+		if len(cells) == 1 {
+			section, t = findWeeklySection(cells[0]), nil
+			continue
+		}
+		if section == nil {
+			continue
+		}
+		if t == nil {
+			headers := make([]string, len(cells))
+			for j, c := range cells {
+				headers[j] = normalizeHeader(c)
+			}
+			columns, missing := mapColumns(headers, section.columns)
+			if missing != "" {
+				return nil, errLayoutChanged(url, fmt.Sprintf("%s column %q", section.name, missing))
+			}
+			t, found[section] = &table{columns: columns}, true
+			continue
+		}
+		section.parse(p, t, cells, stats)
+		if p.err != nil {
+			return nil, p.err
+		}
+	}
+
+	for _, s := range weeklySections {
+		if !found[s] {
+			return nil, errLayoutChanged(url, s.name+" table")
+		}
+	}
+	return stats, nil
+}
+
+// findWeeklySection returns the section printed under the heading or nil if it is not a heading of a section
+func findWeeklySection(heading string) *weeklySection {
+	heading = normalizeHeader(heading)
+	for _, s := range weeklySections {
+		for _, h := range s.headings {
+			if heading == normalizeHeader(h) {
+				return s
+			}
+		}
+	}
+	return nil
+}
+
+// parseWeeklyIndex reads a row of the index movement table, ex: "CASPI", "14,256.09", "14,102.33", "153.76", "1.09%"
+func parseWeeklyIndex(p *cellParser, t *table, cells []string, s *WeeklyStatistics) {
+	s.Indices = append(s.Indices, &WeeklyIndexMovement{
+		Name:             t.cell(cells, "index"),
+		ThisWeek:         p.float64("this_week", t.cell(cells, "this_week")),
+		LastWeek:         p.float64("last_week", t.cell(cells, "last_week")),
+		Change:           p.float64("change", t.cell(cells, "change")),
+		ChangePercentage: p.float64("change_percentage", strings.TrimSuffix(t.cell(cells, "change_percentage"), "%")),
+	})
+}
+
+// parseWeeklyTotals reads a row of the market turnover table, ex: "Volume", "231,456,789", "198,765,432".
+// A row of another particular is skipped
+func parseWeeklyTotals(p *cellParser, t *table, cells []string, s *WeeklyStatistics) {
+	this, last := t.cell(cells, "this_week"), t.cell(cells, "last_week")
+	switch particular := normalizeHeader(t.cell(cells, "particulars")); {
+	case strings.Contains(particular, "TRADE"):
+		s.ThisWeek.Trade, s.LastWeek.Trade = p.int64("trade", this), p.int64("trade", last)
+	case strings.Contains(particular, "VOLUME"):
+		s.ThisWeek.Volume, s.LastWeek.Volume = p.int64("volume", this), p.int64("volume", last)
+	case strings.Contains(particular, "CAPITALIZATION"):
+		s.ThisWeek.MarketCapInMN, s.LastWeek.MarketCapInMN = p.decimal("market_cap", this), p.decimal("market_cap", last)
+	case strings.Contains(particular, "VALUE"), strings.Contains(particular, "TURNOVER"):
+		s.ThisWeek.ValueInMN, s.LastWeek.ValueInMN = p.decimal("value", this), p.decimal("value", last)
+	}
+}
+
+// parseWeeklyMover reads a row of the top gainers or the top losers table, ex: "BEXIMCO", "41.10", "38.20", "7.59%"
+func parseWeeklyMover(p *cellParser, t *table, cells []string) *WeeklyMover {
+	return &WeeklyMover{
+		TradingCode:        t.cell(cells, "trading_code"),
+		ClosePrice:         p.decimal("close_price", t.cell(cells, "close_price")),
+		LastWeekClosePrice: p.decimal("last_week_close_price", t.cell(cells, "last_week_close_price")),
+		ChangePercentage:   p.float64("change_percentage", strings.TrimSuffix(t.cell(cells, "change_percentage"), "%")),
+	}
+}
+
+// parseWeeklyTurnover reads a row of the top turnover table, ex: "BEXIMCO", "1,212,455", "48.917"
+func parseWeeklyTurnover(p *cellParser, t *table, cells []string, s *WeeklyStatistics) {
+	s.TopTurnover = append(s.TopTurnover, &WeeklyTurnover{
+		TradingCode: t.cell(cells, "trading_code"),
+		Volume:      p.int64("volume", t.cell(cells, "volume")),
+		ValueInMN:   p.decimal("value", t.cell(cells, "value")),
+	})
+}
+
+// readPDFRows returns the text of every page of the pdf as rows of cells from the top to the bottom of the page.
+// The glyphs printed on the same baseline are a row and a gap of more than a font size between two glyphs starts
+// a new cell
+func readPDFRows(r io.ReaderAt, size int64) (rows [][]string, err error) {
+	// the pdf reader panics on a malformed file instead of returning an error
+	defer func() {
+		if v := recover(); v != nil {
+			rows, err = nil, fmt.Errorf("%v", v)
+		}
+	}()
+
+	reader, err := pdf.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		rows = append(rows, pdfPageRows(page.Content().Text)...)
+	}
+	return rows, nil
+}
+
+// pdfPageRows groups the glyphs of a page into rows of cells
+func pdfPageRows(glyphs []pdf.Text) [][]string {
+	sort.SliceStable(glyphs, func(i, j int) bool {
+		return glyphs[i].Y > glyphs[j].Y
+	})
+
+	var rows [][]string
+	for len(glyphs) > 0 {
+		n := 1
+		for n < len(glyphs) && glyphs[0].Y-glyphs[n].Y <= 1 {
+			n++
+		}
+		line := glyphs[:n]
+		glyphs = glyphs[n:]
+		sort.SliceStable(line, func(i, j int) bool {
+			return line[i].X < line[j].X
+		})
+
+		var cells []string
+		var b strings.Builder
+		flush := func() {
+			if text := cleanText(b.String()); text != "" {
+				cells = append(cells, text)
+			}
+			b.Reset()
+		}
+		for i, g := range line {
+			if i > 0 {
+				prev := line[i-1]
+				gap := g.X - (prev.X + prev.W)
+				switch {
+				case gap > g.FontSize:
+					flush()
+				case gap > g.FontSize/8:
+					b.WriteByte(' ')
+				}
+			}
+			b.WriteString(g.S)
+		}
+		flush()
+		if len(cells) > 0 {
+			rows = append(rows, cells)
+		}
+	}
+	return rows
+}
@@ -0,0 +1,121 @@
+package bdstockexchange
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ledongthuc/pdf"
+)
+
+const testWeeklyReportLink = "https://www.cse.com.bd/assets/weekly_report/weekly_report_20201015.pdf"
+
+func TestCSE_GetWeeklyReport(t *testing.T) {
+	got, err := testCSE().GetWeeklyReport(testWeeklyReportLink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "cse_weekly_report", got)
+
+	if _, err := testCSE().GetWeeklyReport("https://www.cse.com.bd/market/current_price"); !errors.Is(err, ErrLayoutChanged) {
+		t.Errorf("CSE.GetWeeklyReport() of a html page error = %v, want ErrLayoutChanged", err)
+	}
+	if _, err := testCSE().GetWeeklyReport("https://www.cse.com.bd/"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("CSE.GetWeeklyReport() of a link without a file error = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestCSE_GetWeeklyReport_relative(t *testing.T) {
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.RequestURI()
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+
+	NewCSE(WithBaseURL(ts.URL)).GetWeeklyReport("assets/weekly_report/weekly_report_20201015.pdf?v=2")
+	if want := "/assets/weekly_report/weekly_report_20201015.pdf?v=2"; got != want {
+		t.Errorf("GetWeeklyReport() of a relative link requested %q, want %q", got, want)
+	}
+}
+
+func TestCSE_GetWeeklyReport_cache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bdstockexchange")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cse := NewCSE(WithHTTPClient(&http.Client{Transport: testTransport()}), WithCacheDir(dir))
+	want, err := cse.GetWeeklyReport(testWeeklyReportLink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "www.cse.com.bd", "assets", "weekly_report", "weekly_report_20201015.pdf")); err != nil {
+		t.Fatalf("GetWeeklyReport() did not cache the pdf: %v", err)
+	}
+	if _, err := cse.GetWeeklyReport("https://www.cse.com.bd/market/current_price"); err == nil {
+		t.Fatal("GetWeeklyReport() of a html page error = nil")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "www.cse.com.bd", "market", "current_price")); !os.IsNotExist(err) {
+		t.Errorf("GetWeeklyReport() cached a html page")
+	}
+
+	// the cached pdf is read without sending a request
+	offline := NewCSE(WithHTTPClient(&http.Client{Transport: NewReplayTransport(dir)}), WithCacheDir(dir))
+	got, err := offline.GetWeeklyReport(testWeeklyReportLink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetWeeklyReport() from the cache = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseWeeklyReport(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "www.cse.com.bd", "assets", "weekly_report", "weekly_report_20201015.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	got, err := ParseWeeklyReport(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "cse_weekly_report", got)
+
+	for _, b := range []string{"", "%PDF-1.4\n", "<html></html>"} {
+		if _, err := readWeeklyReport(strings.NewReader(b), int64(len(b)), "weekly report"); !errors.Is(err, ErrLayoutChanged) {
+			t.Errorf("readWeeklyReport(%q) error = %v, want ErrLayoutChanged", b, err)
+		}
+	}
+}
+
+func Test_pdfPageRows(t *testing.T) {
+	glyphs := func(x, y float64, s string) []pdf.Text {
+		var text []pdf.Text
+		for _, r := range s {
+			text = append(text, pdf.Text{FontSize: 10, X: x, Y: y, W: 5, S: string(r)})
+			x += 5
+		}
+		return text
+	}
+	var page []pdf.Text
+	page = append(page, glyphs(100, 700, "1.5")...)
+	page = append(page, glyphs(10, 700.5, "GP")...)
+	page = append(page, glyphs(10, 720, "Top Gainers")...)
+	page = append(page, glyphs(10, 680, "Trading")...)
+	page = append(page, glyphs(47, 680, "Code")...)
+
+	got := pdfPageRows(page)
+	want := [][]string{{"Top Gainers"}, {"GP", "1.5"}, {"Trading Code"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pdfPageRows() = %q, want %q", got, want)
+	}
+}